@@ -0,0 +1,298 @@
+// Package activity filters the activity events of an account, and keeps track
+// of the events already seen while following them.
+package activity
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/events"
+)
+
+// Types lists the event types which can be filtered on, by their short name.
+var Types = map[string]events.Type{
+	"provisioned":   events.TypeOperationProvisioned,
+	"deprovisioned": events.TypeOperationDeprovisioned,
+	"resized":       events.TypeOperationResized,
+	"failed":        events.TypeOperationFailed,
+	"measured":      events.TypeResourceMeasuresAdded,
+}
+
+// Sources lists the sources an event can originate from.
+var Sources = []events.SourceType{events.SourceCLI, events.SourceDashboard, events.SourceSystem}
+
+// Filter selects events. Empty fields match every event, values of a field
+// are alternatives.
+type Filter struct {
+	Types   []events.Type
+	Sources []events.SourceType
+
+	// Actors, Projects and Resources match either the ID or the name of the
+	// object, names ignoring case. Actors also match an email.
+	Actors    []string
+	Projects  []string
+	Resources []string
+
+	// Since is inclusive, Until is exclusive
+	Since time.Time
+	Until time.Time
+}
+
+// ParseType returns the event type matching either its short name, such as
+// provisioned, or its full name, such as operation.provisioned.
+func ParseType(s string) (events.Type, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if t, ok := Types[s]; ok {
+		return t, nil
+	}
+
+	for _, t := range Types {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unknown event type %q, expected one of %s", s,
+		strings.Join(typeNames(), ", "))
+}
+
+// ParseSource returns the source matching s.
+func ParseSource(s string) (events.SourceType, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, src := range Sources {
+		if string(src) == s {
+			return src, nil
+		}
+	}
+
+	names := make([]string, len(Sources))
+	for i, src := range Sources {
+		names[i] = string(src)
+	}
+
+	return "", fmt.Errorf("Unknown event source %q, expected one of %s", s,
+		strings.Join(names, ", "))
+}
+
+// ParseTime reads a point in time either as a date, a RFC 3339 timestamp or
+// a duration before now, such as 90m, 12h or 7d.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("Invalid time %q, expected a date (2006-01-02), "+
+		"a RFC 3339 timestamp or a duration such as 12h or 7d", s)
+}
+
+// Apply returns the events matching the filter, keeping their order.
+func (f *Filter) Apply(evts []*events.Event) []*events.Event {
+	var matches []*events.Event
+	for _, e := range evts {
+		if f.Match(e) {
+			matches = append(matches, e)
+		}
+	}
+
+	return matches
+}
+
+// Match returns whether the event is selected by the filter.
+func (f *Filter) Match(e *events.Event) bool {
+	if len(f.Types) > 0 && !containsType(f.Types, e.Body.Type()) {
+		return false
+	}
+
+	if len(f.Sources) > 0 {
+		src := e.Body.Source()
+		if src == nil || !containsSource(f.Sources, events.SourceType(*src)) {
+			return false
+		}
+	}
+
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		at := createdAt(e)
+		if !f.Since.IsZero() && at.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && !at.Before(f.Until) {
+			return false
+		}
+	}
+
+	if len(f.Actors) > 0 {
+		actor := e.Body.Actor()
+		id := e.Body.ActorID()
+		var name, email string
+		if actor != nil {
+			name, email = actor.Name, actor.Email
+		}
+
+		if !matchAny(f.Actors, &id, name) && (email == "" || !matchAny(f.Actors, nil, email)) {
+			return false
+		}
+	}
+
+	d := dataOf(e)
+
+	if len(f.Projects) > 0 {
+		var name string
+		if d.project != nil {
+			name = d.project.Name
+		}
+		if !matchAny(f.Projects, d.projectID, name) {
+			return false
+		}
+	}
+
+	if len(f.Resources) > 0 {
+		var name string
+		if d.resource != nil {
+			name = d.resource.Name
+		}
+		if !matchAny(f.Resources, d.resourceID, name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Sort orders events from the oldest to the most recent.
+func Sort(evts []*events.Event) {
+	sort.SliceStable(evts, func(i, j int) bool {
+		return createdAt(evts[i]).Before(createdAt(evts[j]))
+	})
+}
+
+// Tail remembers the events already seen while polling for new ones.
+type Tail struct {
+	seen map[manifold.ID]bool
+}
+
+// NewTail returns a Tail which has seen none of the events.
+func NewTail() *Tail {
+	return &Tail{seen: make(map[manifold.ID]bool)}
+}
+
+// Next returns the events which were not seen before, oldest first, and
+// marks them as seen.
+func (t *Tail) Next(evts []*events.Event) []*events.Event {
+	var unseen []*events.Event
+	for _, e := range evts {
+		if t.seen[e.ID] {
+			continue
+		}
+
+		t.seen[e.ID] = true
+		unseen = append(unseen, e)
+	}
+
+	Sort(unseen)
+	return unseen
+}
+
+// data holds the objects an operation event refers to.
+type data struct {
+	resourceID *manifold.ID
+	resource   *events.Resource
+	projectID  *manifold.ID
+	project    *events.Project
+}
+
+func dataOf(e *events.Event) data {
+	switch body := e.Body.(type) {
+	case *events.OperationProvisioned:
+		if d := body.Data; d != nil {
+			return data{&d.ResourceID, d.Resource, d.ProjectID, d.Project}
+		}
+	case *events.OperationDeprovisioned:
+		if d := body.Data; d != nil {
+			return data{&d.ResourceID, d.Resource, d.ProjectID, d.Project}
+		}
+	case *events.OperationResized:
+		if d := body.Data; d != nil {
+			return data{&d.ResourceID, d.Resource, d.ProjectID, d.Project}
+		}
+	case *events.OperationFailed:
+		if d := body.Data; d != nil {
+			return data{d.ResourceID, d.Resource, d.ProjectID, d.Project}
+		}
+	case *events.ResourceMeasuresAdded:
+		if d := body.Data; d != nil {
+			return data{&d.ResourceID, d.Resource, d.ProjectID, d.Project}
+		}
+	}
+
+	return data{}
+}
+
+func createdAt(e *events.Event) time.Time {
+	if at := e.Body.CreatedAt(); at != nil {
+		return time.Time(*at)
+	}
+
+	return time.Time{}
+}
+
+// matchAny returns whether one of the values is the ID or the name.
+func matchAny(values []string, id *manifold.ID, name string) bool {
+	for _, v := range values {
+		if id != nil && !id.IsEmpty() && v == id.String() {
+			return true
+		}
+		if name != "" && strings.EqualFold(v, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsType(types []events.Type, t events.Type) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsSource(sources []events.SourceType, s events.SourceType) bool {
+	for _, v := range sources {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func typeNames() []string {
+	names := make([]string, 0, len(Types))
+	for name := range Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
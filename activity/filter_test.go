@@ -0,0 +1,168 @@
+package activity
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/events"
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+func newEvent(t *testing.T, typ events.Type, source events.SourceType, actor, project,
+	resource string, at time.Time) *events.Event {
+	id, err := manifold.NewID(idtype.ActivityEvent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	actorID, err := manifold.NewID(idtype.User)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	base := events.BaseBody{
+		EventType:       typ,
+		StructActorID:   actorID,
+		StructActor:     &events.Actor{Name: actor, Email: actor + "@example.com"},
+		StructCreatedAt: at,
+		StructSource:    source,
+	}
+	data := &events.OperationProvisionedData{
+		Resource: &events.Resource{Name: resource},
+		Project:  &events.Project{Name: project},
+	}
+
+	var body events.Body
+	switch typ {
+	case events.TypeOperationDeprovisioned:
+		body = &events.OperationDeprovisioned{BaseBody: base,
+			Data: (*events.OperationDeprovisionedData)(data)}
+	default:
+		body = &events.OperationProvisioned{BaseBody: base, Data: data}
+	}
+
+	return &events.Event{ID: id, StructType: "activity_event", StructVersion: 1, Body: body}
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	evts := []*events.Event{
+		newEvent(t, events.TypeOperationProvisioned, events.SourceCLI, "ada", "web", "db",
+			now.Add(-time.Hour)),
+		newEvent(t, events.TypeOperationDeprovisioned, events.SourceDashboard, "grace", "web",
+			"cache", now.Add(-48*time.Hour)),
+		newEvent(t, events.TypeOperationProvisioned, events.SourceSystem, "grace", "api",
+			"logs", now.Add(-10*24*time.Hour)),
+	}
+
+	tcs := []struct {
+		name    string
+		filter  Filter
+		matches []int
+	}{
+		{"none", Filter{}, []int{0, 1, 2}},
+		{"type", Filter{Types: []events.Type{events.TypeOperationDeprovisioned}}, []int{1}},
+		{"source", Filter{Sources: []events.SourceType{events.SourceCLI, events.SourceSystem}},
+			[]int{0, 2}},
+		{"actor name", Filter{Actors: []string{"Grace"}}, []int{1, 2}},
+		{"actor email", Filter{Actors: []string{"ada@example.com"}}, []int{0}},
+		{"actor id", Filter{Actors: []string{evts[0].Body.ActorID().String()}}, []int{0}},
+		{"project", Filter{Projects: []string{"web"}}, []int{0, 1}},
+		{"resource", Filter{Resources: []string{"logs", "db"}}, []int{0, 2}},
+		{"since", Filter{Since: now.Add(-72 * time.Hour)}, []int{0, 1}},
+		{"until", Filter{Until: now.Add(-time.Hour)}, []int{1, 2}},
+		{"combined", Filter{Actors: []string{"grace"}, Projects: []string{"web"}}, []int{1}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.filter.Apply(evts)
+			if len(got) != len(tc.matches) {
+				t.Fatalf("Expected %d events, got %d", len(tc.matches), len(got))
+			}
+			for i, m := range tc.matches {
+				if got[i] != evts[m] {
+					t.Errorf("Expected event %d at %d", m, i)
+				}
+			}
+		})
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for _, s := range []string{"resized", "operation.resized", " Resized "} {
+		typ, err := ParseType(s)
+		if err != nil || typ != events.TypeOperationResized {
+			t.Errorf("Expected %q to parse as resized, got %q (%v)", s, typ, err)
+		}
+	}
+
+	if _, err := ParseType("created"); err == nil {
+		t.Error("Expected an error for an unknown type")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tcs := []struct {
+		in  string
+		out time.Time
+	}{
+		{"2026-10-01", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{"2026-10-01T08:30:00Z", time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.AddDate(0, 0, -7)},
+	}
+
+	for _, tc := range tcs {
+		got, err := ParseTime(tc.in, now)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %s", tc.in, err)
+			continue
+		}
+		if !got.Equal(tc.out) {
+			t.Errorf("Expected %q to be %s, got %s", tc.in, tc.out, got)
+		}
+	}
+
+	for _, in := range []string{"yesterday", "-3d", "-1h"} {
+		if _, err := ParseTime(in, now); err == nil {
+			t.Errorf("Expected an error for %q", in)
+		}
+	}
+}
+
+func TestTail(t *testing.T) {
+	now := time.Now()
+	older := newEvent(t, events.TypeOperationProvisioned, events.SourceCLI, "ada", "web", "db",
+		now.Add(-time.Hour))
+	newer := newEvent(t, events.TypeOperationResized, events.SourceCLI, "ada", "web", "db", now)
+
+	tail := NewTail()
+	if got := tail.Next([]*events.Event{older}); len(got) != 1 || got[0] != older {
+		t.Fatalf("Expected the first event, got %d events", len(got))
+	}
+
+	// Events are listed again on every poll, and from a fresh decode
+	b, err := json.Marshal([]*events.Event{newer, older})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var polled []*events.Event
+	if err := json.Unmarshal(b, &polled); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	got := tail.Next(polled)
+	if len(got) != 1 || got[0].ID != newer.ID {
+		t.Fatalf("Expected only the new event, got %d events", len(got))
+	}
+
+	if got := tail.Next(polled); len(got) != 0 {
+		t.Errorf("Expected no new events, got %d", len(got))
+	}
+}
@@ -0,0 +1,164 @@
+// Package audit turns activity events into audit log records, written as JSON
+// Lines, CSV or RFC 5424 syslog messages.
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/manifoldco/go-manifold/events"
+)
+
+// Record is the audit log entry of an event. Plan is the plan a resource was
+// provisioned on, or resized to when OldPlan is set.
+type Record struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	ActorID    string    `json:"actor_id"`
+	Actor      string    `json:"actor,omitempty"`
+	ActorEmail string    `json:"actor_email,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	ScopeID    string    `json:"scope_id"`
+	Scope      string    `json:"scope,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	ProjectID  string    `json:"project_id,omitempty"`
+	Project    string    `json:"project,omitempty"`
+	ResourceID string    `json:"resource_id,omitempty"`
+	Resource   string    `json:"resource,omitempty"`
+	Product    string    `json:"product,omitempty"`
+	Plan       string    `json:"plan,omitempty"`
+	OldPlan    string    `json:"old_plan,omitempty"`
+}
+
+// NewRecord returns the audit record of an event.
+func NewRecord(e *events.Event) Record {
+	r := Record{
+		ID:        e.ID.String(),
+		Type:      string(e.Body.Type()),
+		ActorID:   e.Body.ActorID().String(),
+		ScopeID:   e.Body.ScopeID().String(),
+		IPAddress: e.Body.IPAddress(),
+	}
+
+	if at := e.Body.CreatedAt(); at != nil {
+		r.OccurredAt = time.Time(*at).UTC()
+	}
+	if actor := e.Body.Actor(); actor != nil {
+		r.Actor = actor.Name
+		r.ActorEmail = actor.Email
+	}
+	if scope := e.Body.Scope(); scope != nil {
+		r.Scope = scope.Name
+	}
+	if source := e.Body.Source(); source != nil {
+		r.Source = *source
+	}
+
+	switch body := e.Body.(type) {
+	case *events.OperationProvisioned:
+		if d := body.Data; d != nil {
+			r.ResourceID = d.ResourceID.String()
+			r.setData(d.Resource, d.Project, d.Product, d.Plan)
+		}
+	case *events.OperationDeprovisioned:
+		if d := body.Data; d != nil {
+			r.ResourceID = d.ResourceID.String()
+			r.setData(d.Resource, d.Project, d.Product, d.Plan)
+		}
+	case *events.OperationResized:
+		if d := body.Data; d != nil {
+			r.ResourceID = d.ResourceID.String()
+			r.setData(d.Resource, d.Project, d.Product, d.NewPlan)
+			if d.OldPlan != nil {
+				r.OldPlan = d.OldPlan.Name
+			}
+		}
+	}
+
+	return r
+}
+
+func (r *Record) setData(resource *events.Resource, project *events.Project,
+	product *events.Product, plan *events.Plan) {
+	if resource != nil {
+		r.Resource = resource.Name
+	}
+	if project != nil {
+		r.ProjectID = project.ID.String()
+		r.Project = project.Name
+	}
+	if product != nil {
+		r.Product = product.Name
+	}
+	if plan != nil {
+		r.Plan = plan.Name
+	}
+}
+
+// Writer writes audit records to a destination.
+type Writer interface {
+	Write(Record) error
+
+	// Flush writes any buffered record, returning the first error which
+	// occurred while writing.
+	Flush() error
+}
+
+// NewJSONLines returns a Writer writing every record as a JSON object on its
+// own line.
+func NewJSONLines(w io.Writer) Writer {
+	return &jsonLines{enc: json.NewEncoder(w)}
+}
+
+type jsonLines struct {
+	enc *json.Encoder
+}
+
+func (j *jsonLines) Write(r Record) error { return j.enc.Encode(r) }
+func (j *jsonLines) Flush() error         { return nil }
+
+// CSVHeader lists the columns written by the CSV writer.
+var CSVHeader = []string{
+	"id", "type", "occurred_at", "actor_id", "actor", "actor_email", "ip_address",
+	"scope_id", "scope", "source", "project_id", "project", "resource_id", "resource",
+	"product", "plan", "old_plan",
+}
+
+// NewCSV returns a Writer writing records as CSV rows, preceded by CSVHeader
+// when header is true. Files appended to already have their header.
+func NewCSV(w io.Writer, header bool) Writer {
+	return &csvWriter{w: csv.NewWriter(w), header: header}
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) Write(r Record) error {
+	if c.header {
+		c.header = false
+		if err := c.w.Write(CSVHeader); err != nil {
+			return err
+		}
+	}
+
+	var at string
+	if !r.OccurredAt.IsZero() {
+		at = r.OccurredAt.Format(time.RFC3339)
+	}
+
+	return c.w.Write([]string{
+		r.ID, r.Type, at, r.ActorID, r.Actor, r.ActorEmail, r.IPAddress,
+		r.ScopeID, r.Scope, r.Source, r.ProjectID, r.Project, r.ResourceID, r.Resource,
+		r.Product, r.Plan, r.OldPlan,
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/events"
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+func resized(t *testing.T) *events.Event {
+	id, err := manifold.NewID(idtype.ActivityEvent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return &events.Event{
+		ID:            id,
+		StructType:    "activity_event",
+		StructVersion: 1,
+		Body: &events.OperationResized{
+			BaseBody: events.BaseBody{
+				EventType:       events.TypeOperationResized,
+				StructActor:     &events.Actor{Name: "Ada", Email: "ada@example.com"},
+				StructCreatedAt: time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC),
+				StructSource:    events.SourceCLI,
+				StructIPAddress: "10.0.0.1",
+			},
+			Data: &events.OperationResizedData{
+				Resource: &events.Resource{Name: "db"},
+				Project:  &events.Project{Name: "web"},
+				Product:  &events.Product{Name: "JawsDB"},
+				OldPlan:  &events.Plan{Name: "kitefin"},
+				NewPlan:  &events.Plan{Name: "shark"},
+			},
+		},
+	}
+}
+
+func TestNewRecord(t *testing.T) {
+	r := NewRecord(resized(t))
+
+	if r.Actor != "Ada" || r.ActorEmail != "ada@example.com" || r.IPAddress != "10.0.0.1" ||
+		r.Source != "cli" || r.Resource != "db" || r.Plan != "shark" || r.OldPlan != "kitefin" {
+		t.Errorf("Unexpected record %+v", r)
+	}
+}
+
+func TestWriters(t *testing.T) {
+	r := NewRecord(resized(t))
+
+	t.Run("json lines", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewJSONLines(&buf)
+		for i := 0; i < 2; i++ {
+			if err := w.Write(r); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 lines, got %d", len(lines))
+		}
+
+		var got Record
+		if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if got != r {
+			t.Errorf("Expected %+v, got %+v", r, got)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewCSV(&buf, true)
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(rows) != 2 || rows[0][0] != "id" || rows[1][2] != "2026-10-01T08:30:00Z" {
+			t.Errorf("Unexpected rows %v", rows)
+		}
+	})
+
+	t.Run("syslog", func(t *testing.T) {
+		msg, err := FormatSyslog(r, "build host", "0.15.0", 42)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		prefix := `<110>1 2026-10-01T08:30:00Z buildhost manifold 42 operation.resized ` +
+			`[origin software="manifold-cli" swVersion="0.15.0"] {`
+		if !strings.HasPrefix(string(msg), prefix) {
+			t.Errorf("Unexpected message %s", msg)
+		}
+	})
+}
+
+func TestDialSyslog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "log")
+	l, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("Unix datagram sockets are unavailable: %s", err)
+	}
+	defer l.Close()
+
+	conn, err := DialSyslog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer conn.Close()
+
+	w := NewSyslog(conn, "0.15.0")
+	if err := w.Write(NewRecord(resized(t))); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	buf := make([]byte, 4096)
+	n, err := l.Read(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<110>1 ") || !strings.HasSuffix(msg, "}\n") {
+		t.Errorf("Unexpected message %q", msg)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	at := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	records := []Record{
+		{ID: "c", OccurredAt: at.Add(2 * time.Hour)},
+		{ID: "a", OccurredAt: at},
+		{ID: "b", OccurredAt: at.Add(time.Hour)},
+	}
+	Sort(records)
+
+	ids := func(records []Record) string {
+		var s string
+		for _, r := range records {
+			s += r.ID
+		}
+		return s
+	}
+
+	tcs := []struct {
+		name string
+		mark *Mark
+		out  string
+	}{
+		{"none", nil, "abc"},
+		{"listed", &Mark{ID: "a", OccurredAt: at}, "bc"},
+		{"last", &Mark{ID: "c", OccurredAt: at.Add(2 * time.Hour)}, ""},
+		{"removed", &Mark{ID: "x", OccurredAt: at.Add(90 * time.Minute)}, "c"},
+	}
+
+	for _, tc := range tcs {
+		if got := ids(After(records, tc.mark)); got != tc.out {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.out, got)
+		}
+	}
+}
+
+func TestMarks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "marks")
+	marks, err := LoadMarks(path)
+	if err != nil || len(marks) != 0 {
+		t.Fatalf("Expected no marks, got %v (%v)", marks, err)
+	}
+
+	key := MarkKey("team", "audit.jsonl")
+	marks[key] = Mark{ID: "a", OccurredAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}
+	if err := SaveMarks(path, marks); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	loaded, err := LoadMarks(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if loaded[key] != marks[key] {
+		t.Errorf("Expected %v, got %v", marks[key], loaded[key])
+	}
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Mark is the high-water mark of an export, the last record it wrote.
+type Mark struct {
+	ID         string    `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// MarkOf returns the mark of a record.
+func MarkOf(r Record) Mark {
+	return Mark{ID: r.ID, OccurredAt: r.OccurredAt}
+}
+
+// Sort orders records from the oldest to the most recent.
+func Sort(records []Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].OccurredAt.Before(records[j].OccurredAt)
+	})
+}
+
+// After returns the records following the mark, which must be sorted oldest
+// first. When the marked record isn't listed anymore, the records which
+// occurred after it are returned instead. Every record follows a nil mark.
+func After(records []Record, m *Mark) []Record {
+	if m == nil {
+		return records
+	}
+
+	for i, r := range records {
+		if r.ID == m.ID {
+			return records[i+1:]
+		}
+	}
+
+	for i, r := range records {
+		if r.OccurredAt.After(m.OccurredAt) {
+			return records[i:]
+		}
+	}
+
+	return nil
+}
+
+// MarkKey returns the key of the mark of exports of a scope to a destination.
+func MarkKey(scopeID, destination string) string {
+	return scopeID + " " + destination
+}
+
+// LoadMarks reads the marks stored at path. No marks are returned when the
+// file doesn't exist yet.
+func LoadMarks(path string) (map[string]Mark, error) {
+	marks := make(map[string]Mark)
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return marks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &marks); err != nil {
+		return nil, err
+	}
+
+	return marks, nil
+}
+
+// SaveMarks replaces the marks stored at path.
+func SaveMarks(path string, marks map[string]Mark) error {
+	b, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// priority is the facility and severity of messages: log audit (13) and
+// informational (6).
+const priority = 13*8 + 6
+
+// syslogTime is RFC 3339 with at most microseconds, as RFC 5424 requires.
+const syslogTime = "2006-01-02T15:04:05.999999Z07:00"
+
+// DefaultSockets lists the local syslog sockets tried in order when none is
+// given.
+var DefaultSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// ErrNoSyslog is returned when no local syslog socket accepts connections.
+var ErrNoSyslog = errors.New("No local syslog socket found, use --syslog-socket to set one")
+
+// DialSyslog connects to the local syslog daemon listening on path, or on the
+// first of DefaultSockets accepting connections when path is empty.
+func DialSyslog(path string) (net.Conn, error) {
+	paths := DefaultSockets
+	if path != "" {
+		paths = []string{path}
+	}
+
+	var lastErr error
+	for _, p := range paths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, p)
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+	}
+
+	if path != "" {
+		return nil, lastErr
+	}
+
+	return nil, ErrNoSyslog
+}
+
+// NewSyslog returns a Writer sending every record as its own RFC 5424
+// message, tagged with the version of the CLI.
+func NewSyslog(w io.Writer, version string) Writer {
+	hostname, _ := os.Hostname()
+	return &syslogWriter{w: w, hostname: hostname, version: version, pid: os.Getpid()}
+}
+
+type syslogWriter struct {
+	w        io.Writer
+	hostname string
+	version  string
+	pid      int
+}
+
+func (s *syslogWriter) Write(r Record) error {
+	msg, err := FormatSyslog(r, s.hostname, s.version, s.pid)
+	if err != nil {
+		return err
+	}
+
+	// Every message is written at once, as datagram sockets expect
+	_, err = s.w.Write(append(msg, '\n'))
+	return err
+}
+
+func (s *syslogWriter) Flush() error { return nil }
+
+// FormatSyslog returns the RFC 5424 message of a record. The event type is
+// the MSGID, the time of the event the TIMESTAMP and the record, as JSON, the
+// MSG.
+func FormatSyslog(r Record, hostname, version string, pid int) ([]byte, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := "-"
+	if !r.OccurredAt.IsZero() {
+		ts = r.OccurredAt.Format(syslogTime)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s manifold %d %s [origin software=\"manifold-cli\" swVersion=\"%s\"] ",
+		priority, ts, header(hostname, 255), pid, header(r.Type, 32), sdEscape(version))
+	buf.Write(body)
+
+	return buf.Bytes(), nil
+}
+
+// header returns a header field as RFC 5424 allows it: printable ASCII
+// without spaces, truncated to max, or - when empty.
+func header(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+
+	if s == "" {
+		return "-"
+	}
+	if len(s) > max {
+		return s[:max]
+	}
+
+	return s
+}
+
+// sdEscape escapes the characters RFC 5424 reserves in parameter values.
+func sdEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
@@ -102,21 +102,12 @@
     local SHELLTYPE
     SHELLTYPE="$(basename "/$SHELL")"
 
-    if [ "$SHELLTYPE" = "bash" ]; then
-      AUTOCOMPLETE=$(cat <<'EOF'
-#! /bin/bash
-export MANIFOLD_AUTOCOMPLETE=true
-_manifold_bash_autocomplete() {
-  local cur opts base
-  COMPREPLY=()
-  cur="${COMP_WORDS[COMP_CWORD]}"
-  opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion )
-  COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
-  return 0
-}
-complete -F _manifold_bash_autocomplete manifold
-EOF
-)
+    # eval rather than process substitution, which the bash 3.2 shipped with
+    # macOS doesn't support when sourcing
+    if [ "$SHELLTYPE" = "bash" ] || [ "$SHELLTYPE" = "zsh" ]; then
+      AUTOCOMPLETE="eval \"\$(manifold completion $SHELLTYPE)\""
+    elif [ "$SHELLTYPE" = "fish" ]; then
+      AUTOCOMPLETE="manifold completion fish | source"
     fi
 
     if [ ! -z "$AUTOCOMPLETE" ]; then
@@ -124,7 +115,6 @@ EOF
     fi
   }
 
-  PROFILE=`detect_profile`
   AUTOCOMPLETE=`autocomplete`
 
   if [ -z "$MANIFOLD_DIR" ]; then
@@ -133,6 +123,19 @@ EOF
 
   mkdir -p $MANIFOLD_DIR
 
+  # fish loads completions from its own directory, without a profile
+  if [ "$(basename "/$SHELL")" = "fish" ]; then
+    FISH_COMPLETIONS="${XDG_CONFIG_HOME:-$HOME/.config}/fish/completions"
+    mkdir -p "$FISH_COMPLETIONS"
+    echo "$AUTOCOMPLETE" > "$FISH_COMPLETIONS/manifold.fish"
+
+    success_msg "Installed manifold autocomplete in $FISH_COMPLETIONS/manifold.fish"
+    warning_msg "Please restart your terminal session."
+    exit 0
+  fi
+
+  PROFILE=`detect_profile`
+
   if [ "$PROFILE" = "" ]; then
     error_exit "Unable to locate profile settings file (something like $HOME/.bashrc or $HOME/.bash_profile)"
   elif [ "$MANIFOLD_AUTOCOMPLETE" = "" ]; then
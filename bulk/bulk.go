@@ -0,0 +1,252 @@
+// Package bulk runs resource operations concurrently with a bounded number of
+// workers, starting every operation once the operations it depends on have
+// completed.
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultWorkers is the number of operations run at the same time when no
+// limit is given
+const DefaultWorkers = 4
+
+// ErrCycle is returned when the dependencies of the tasks form a cycle
+var ErrCycle = errors.New("Operations depend on each other in a cycle")
+
+// Status is the progress of a task.
+type Status string
+
+const (
+	// Pending tasks are waiting for a worker or for their dependencies
+	Pending Status = "pending"
+
+	// Running tasks are being applied
+	Running Status = "running"
+
+	// Done tasks completed successfully
+	Done Status = "done"
+
+	// Failed tasks returned an error
+	Failed Status = "failed"
+
+	// Skipped tasks were never started, because a dependency failed or the
+	// operations were cancelled
+	Skipped Status = "skipped"
+)
+
+// Task is a single operation on a resource.
+type Task struct {
+	// Key identifies the task for DependsOn, it defaults to the resource
+	// name and action
+	Key       string
+	Resource  string
+	Action    string
+	DependsOn []string
+
+	// Run applies the operation, calling report with every operation state
+	// it observes
+	Run func(ctx context.Context, report func(state string)) error
+}
+
+func (t *Task) key() string {
+	if t.Key != "" {
+		return t.Key
+	}
+
+	return t.Resource + "/" + t.Action
+}
+
+// Result is the outcome of a task.
+type Result struct {
+	Task    *Task
+	Status  Status
+	State   string
+	Err     error
+	Elapsed time.Duration
+}
+
+type event struct {
+	index int
+	state string
+	done  bool
+	err   error
+}
+
+// Run applies the tasks using at most workers operations at the same time,
+// calling observe with every result whenever one of them changes. The
+// returned results are in the same order as the tasks.
+func Run(ctx context.Context, tasks []Task, workers int, observe func([]Result)) ([]Result, error) {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	dependents, waiting, err := graph(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(tasks))
+	started := make([]time.Time, len(tasks))
+	var ready []int
+	for i := range tasks {
+		results[i] = Result{Task: &tasks[i], Status: Pending}
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	notify := func() {
+		if observe != nil {
+			snapshot := make([]Result, len(results))
+			copy(snapshot, results)
+			observe(snapshot)
+		}
+	}
+
+	events := make(chan event)
+	run := func(i int) {
+		report := func(state string) {
+			events <- event{index: i, state: state}
+		}
+
+		err := tasks[i].Run(ctx, report)
+		events <- event{index: i, done: true, err: err}
+	}
+
+	// skip marks the dependents of a task which didn't complete as skipped
+	var skip func(i int, reason error) int
+	skip = func(i int, reason error) int {
+		n := 0
+		for _, d := range dependents[i] {
+			if results[d].Status != Pending {
+				continue
+			}
+
+			results[d].Status = Skipped
+			results[d].Err = reason
+			n += 1 + skip(d, reason)
+		}
+		return n
+	}
+
+	notify()
+
+	finished, running := 0, 0
+	for finished < len(tasks) {
+		for running < workers && len(ready) > 0 && ctx.Err() == nil {
+			i := ready[0]
+			ready = ready[1:]
+
+			results[i].Status = Running
+			started[i] = time.Now()
+			running++
+			go run(i)
+		}
+
+		if running == 0 {
+			// Cancelled before every task could start
+			for i := range results {
+				if results[i].Status == Pending {
+					results[i].Status = Skipped
+					results[i].Err = ctx.Err()
+					finished++
+				}
+			}
+			break
+		}
+
+		notify()
+
+		e := <-events
+		r := &results[e.index]
+		if !e.done {
+			r.State = e.state
+			continue
+		}
+
+		running--
+		finished++
+		r.Elapsed = time.Since(started[e.index])
+
+		if e.err != nil {
+			r.Status = Failed
+			r.Err = e.err
+			reason := fmt.Errorf("%s %s failed", tasks[e.index].Action, tasks[e.index].Resource)
+			finished += skip(e.index, reason)
+			continue
+		}
+
+		r.Status = Done
+		for _, d := range dependents[e.index] {
+			waiting[d]--
+			if waiting[d] == 0 && results[d].Status == Pending {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	notify()
+	return results, nil
+}
+
+// graph returns the dependents of every task along with the number of
+// dependencies each task is waiting for.
+func graph(tasks []Task) ([][]int, []int, error) {
+	index := make(map[string]int)
+	for i := range tasks {
+		k := tasks[i].key()
+		if _, ok := index[k]; ok {
+			return nil, nil, fmt.Errorf("Operation %q is listed more than once", k)
+		}
+		index[k] = i
+	}
+
+	dependents := make([][]int, len(tasks))
+	waiting := make([]int, len(tasks))
+	for i := range tasks {
+		for _, k := range tasks[i].DependsOn {
+			d, ok := index[k]
+			if !ok {
+				return nil, nil, fmt.Errorf("Operation %q depends on unknown operation %q", tasks[i].key(), k)
+			}
+
+			dependents[d] = append(dependents[d], i)
+			waiting[i]++
+		}
+	}
+
+	// Every task must be reachable by removing tasks without dependencies
+	remaining := make([]int, len(waiting))
+	copy(remaining, waiting)
+
+	var queue []int
+	for i, n := range remaining {
+		if n == 0 {
+			queue = append(queue, i)
+		}
+	}
+
+	visited := 0
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		visited++
+
+		for _, d := range dependents[i] {
+			remaining[d]--
+			if remaining[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	if visited != len(tasks) {
+		return nil, nil, ErrCycle
+	}
+
+	return dependents, waiting, nil
+}
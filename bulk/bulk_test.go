@@ -0,0 +1,116 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("respects dependencies and the worker limit", func(t *testing.T) {
+		var mu sync.Mutex
+		var order []string
+		active, maxActive := 0, 0
+
+		task := func(resource, action string, deps ...string) Task {
+			return Task{
+				Resource:  resource,
+				Action:    action,
+				DependsOn: deps,
+				Run: func(ctx context.Context, report func(string)) error {
+					mu.Lock()
+					active++
+					if active > maxActive {
+						maxActive = active
+					}
+					order = append(order, resource+"/"+action)
+					mu.Unlock()
+
+					report("provisioning")
+
+					mu.Lock()
+					active--
+					mu.Unlock()
+					return nil
+				},
+			}
+		}
+
+		tasks := []Task{
+			task("a", "move"),
+			task("a", "resize", "a/move"),
+			task("b", "delete"),
+			task("c", "delete"),
+		}
+
+		results, err := Run(context.Background(), tasks, 2, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if n := Count(results, Done); n != len(tasks) {
+			t.Errorf("Expected every task to be done, got %d", n)
+		}
+		if maxActive > 2 {
+			t.Errorf("Expected at most 2 workers, got %d", maxActive)
+		}
+
+		move, resize := -1, -1
+		for i, o := range order {
+			switch o {
+			case "a/move":
+				move = i
+			case "a/resize":
+				resize = i
+			}
+		}
+		if move == -1 || resize < move {
+			t.Errorf("Expected resize to run after move, got %v", order)
+		}
+		if results[1].State != "provisioning" {
+			t.Errorf("Expected the reported state to be kept, got %q", results[1].State)
+		}
+	})
+
+	t.Run("skips dependents of failed tasks", func(t *testing.T) {
+		fail := errors.New("boom")
+		tasks := []Task{
+			{Resource: "a", Action: "move", Run: func(context.Context, func(string)) error { return fail }},
+			{Resource: "a", Action: "resize", DependsOn: []string{"a/move"},
+				Run: func(context.Context, func(string)) error { return nil }},
+			{Resource: "b", Action: "delete", Run: func(context.Context, func(string)) error { return nil }},
+		}
+
+		var updates int
+		results, err := Run(context.Background(), tasks, 0, func([]Result) { updates++ })
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expected := []Status{Failed, Skipped, Done}
+		for i, r := range results {
+			if r.Status != expected[i] {
+				t.Errorf("Expected %s for %s, got %s", expected[i], r.Task.key(), r.Status)
+			}
+		}
+		if results[0].Err != fail {
+			t.Errorf("Expected the task error, got %v", results[0].Err)
+		}
+		if updates == 0 {
+			t.Error("Expected progress to be observed")
+		}
+	})
+
+	t.Run("rejects cycles", func(t *testing.T) {
+		noop := func(context.Context, func(string)) error { return nil }
+		tasks := []Task{
+			{Key: "a", DependsOn: []string{"b"}, Run: noop},
+			{Key: "b", DependsOn: []string{"a"}, Run: noop},
+		}
+
+		if _, err := Run(context.Background(), tasks, 1, nil); err != ErrCycle {
+			t.Errorf("Expected ErrCycle, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package bulk
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Progress renders the results of running tasks. Live progress redraws one
+// line per task in place, otherwise a line is written for every change.
+type Progress struct {
+	w     io.Writer
+	live  bool
+	lines int
+	last  []string
+}
+
+// NewProgress returns a Progress writing to w. live should only be set when w
+// is a terminal.
+func NewProgress(w io.Writer, live bool) *Progress {
+	return &Progress{w: w, live: live}
+}
+
+// Update renders the given results.
+func (p *Progress) Update(results []Result) {
+	if p.live {
+		if p.lines > 0 {
+			fmt.Fprintf(p.w, "\x1b[%dA", p.lines)
+		}
+		for _, r := range results {
+			fmt.Fprintf(p.w, "\x1b[2K%s\n", Line(r))
+		}
+		p.lines = len(results)
+		return
+	}
+
+	if len(p.last) != len(results) {
+		p.last = make([]string, len(results))
+	}
+
+	for i, r := range results {
+		line := Line(r)
+		if r.Status == Pending || line == p.last[i] {
+			continue
+		}
+
+		p.last[i] = line
+		fmt.Fprintln(p.w, line)
+	}
+}
+
+// Line describes a single result.
+func Line(r Result) string {
+	line := fmt.Sprintf("%s %-8s %s", symbol(r.Status), r.Task.Action, r.Task.Resource)
+
+	switch r.Status {
+	case Running:
+		if r.State != "" {
+			line += fmt.Sprintf(" (%s)", r.State)
+		}
+	case Done:
+		line += fmt.Sprintf(" (%s)", r.Elapsed.Round(time.Second))
+	case Failed, Skipped:
+		if r.Err != nil {
+			line += ": " + r.Err.Error()
+		}
+	}
+
+	return line
+}
+
+func symbol(s Status) string {
+	switch s {
+	case Running:
+		return "…"
+	case Done:
+		return "✔"
+	case Failed:
+		return "✗"
+	case Skipped:
+		return "-"
+	default:
+		return " "
+	}
+}
+
+// Count returns the number of results with the given status.
+func Count(results []Result, s Status) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == s {
+			n++
+		}
+	}
+
+	return n
+}
@@ -2,15 +2,20 @@ package clients
 
 import (
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/go-openapi/runtime"
 	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
 
 	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/transport"
 
 	aClient "github.com/manifoldco/manifold-cli/generated/activity/client"
 	bClient "github.com/manifoldco/manifold-cli/generated/billing/client"
@@ -27,16 +32,81 @@ const defaultUserAgent = "manifold-cli"
 // Manifold api token
 const EnvManifoldToken string = "MANIFOLD_API_TOKEN"
 
-// newRoundTripper applies a UserAgent header to the transport
-func newRoundTripper(next http.RoundTripper) http.RoundTripper {
+// EnvHTTPRetries and EnvHTTPTimeout override the http_retries and
+// http_timeout keys of the profile.
+const (
+	EnvHTTPRetries = "MANIFOLD_HTTP_RETRIES"
+	EnvHTTPTimeout = "MANIFOLD_HTTP_TIMEOUT"
+)
+
+// tracer traces every request once tracing is enabled.
+var tracer *transport.Tracer
+
+// EnableTracing writes every request sent by the clients created afterwards
+// to w, with secrets redacted. Bodies are only written when bodies is set.
+func EnableTracing(w io.Writer, bodies bool) {
+	tracer = transport.NewTracer(w, bodies)
+}
+
+// newRoundTripper applies a UserAgent header to the transport, and retries
+// failed requests following the retry policy of cfg. Every attempt is traced
+// when tracing is enabled.
+func newRoundTripper(cfg *config.Config, next http.RoundTripper) (http.RoundTripper, error) {
+	policy, err := retryPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if tracer != nil {
+		next = tracer.Wrap(next)
+	}
+
 	version := config.Version
 	if version != "" {
 		version = "-" + version
 	}
 	return &roundTripper{
-		next:      next,
+		next:      transport.NewRetry(next, policy),
 		userAgent: defaultUserAgent + version,
+	}, nil
+}
+
+// retryPolicy reads the number of retries and the timeout of every attempt
+// from the environment, falling back on the profile loaded into cfg.
+func retryPolicy(cfg *config.Config) (transport.Policy, error) {
+	policy := transport.DefaultPolicy
+
+	retries := os.Getenv(EnvHTTPRetries)
+	if retries == "" {
+		retries = cfg.HTTPRetries
+	}
+	if retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 0 {
+			return policy, fmt.Errorf("Invalid http_retries %q, expected a positive number", retries)
+		}
+
+		// The policy disables retries with a negative number, zero uses the
+		// default
+		policy.Retries = n
+		if n == 0 {
+			policy.Retries = -1
+		}
+	}
+
+	timeout := os.Getenv(EnvHTTPTimeout)
+	if timeout == "" {
+		timeout = cfg.HTTPTimeout
+	}
+	if timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return policy, fmt.Errorf("Invalid http_timeout %q, expected a duration such as 30s", timeout)
+		}
+		policy.Timeout = d
 	}
+
+	return policy, nil
 }
 
 type roundTripper struct {
@@ -62,9 +132,12 @@ func NewIdentity(cfg *config.Config) (*iClient.Identity, error) {
 	c.WithSchemes([]string{u.Scheme})
 
 	transport := httptransport.New(c.Host, c.BasePath, c.Schemes)
-	transport.Transport = newRoundTripper(transport.Transport)
+	transport.Transport, err = newRoundTripper(cfg, transport.Transport)
+	if err != nil {
+		return nil, err
+	}
 
-	authToken := retrieveToken(cfg)
+	authToken := RetrieveToken(cfg)
 	if authToken != "" {
 		transport.DefaultAuthentication = NewBearerToken(authToken)
 	}
@@ -85,9 +158,12 @@ func NewMarketplace(cfg *config.Config) (*mClient.Marketplace, error) {
 	c.WithSchemes([]string{u.Scheme})
 
 	transport := httptransport.New(c.Host, c.BasePath, c.Schemes)
-	transport.Transport = newRoundTripper(transport.Transport)
+	transport.Transport, err = newRoundTripper(cfg, transport.Transport)
+	if err != nil {
+		return nil, err
+	}
 
-	authToken := retrieveToken(cfg)
+	authToken := RetrieveToken(cfg)
 	if authToken != "" {
 		transport.DefaultAuthentication = NewBearerToken(authToken)
 	}
@@ -109,8 +185,12 @@ func NewBilling(cfg *config.Config) (*bClient.Billing, error) {
 	c.WithSchemes([]string{u.Scheme})
 
 	transport := httptransport.New(c.Host, c.BasePath, c.Schemes)
+	transport.Transport, err = newRoundTripper(cfg, transport.Transport)
+	if err != nil {
+		return nil, err
+	}
 
-	authToken := retrieveToken(cfg)
+	authToken := RetrieveToken(cfg)
 	if authToken != "" {
 		transport.DefaultAuthentication = NewBearerToken(authToken)
 	}
@@ -131,8 +211,12 @@ func NewActivity(cfg *config.Config) (*aClient.Activity, error) {
 	c.WithSchemes([]string{u.Scheme})
 
 	transport := httptransport.New(c.Host, c.BasePath, c.Schemes)
+	transport.Transport, err = newRoundTripper(cfg, transport.Transport)
+	if err != nil {
+		return nil, err
+	}
 
-	authToken := retrieveToken(cfg)
+	authToken := RetrieveToken(cfg)
 	if authToken != "" {
 		transport.DefaultAuthentication = NewBearerToken(authToken)
 	}
@@ -154,9 +238,12 @@ func NewProvisioning(cfg *config.Config) (*pClient.Provisioning, error) {
 	c.WithSchemes([]string{u.Scheme})
 
 	transport := httptransport.New(c.Host, c.BasePath, c.Schemes)
-	transport.Transport = newRoundTripper(transport.Transport)
+	transport.Transport, err = newRoundTripper(cfg, transport.Transport)
+	if err != nil {
+		return nil, err
+	}
 
-	authToken := retrieveToken(cfg)
+	authToken := RetrieveToken(cfg)
 	if authToken != "" {
 		transport.DefaultAuthentication = NewBearerToken(authToken)
 	}
@@ -177,9 +264,12 @@ func NewConnector(cfg *config.Config) (*conClient.Connector, error) {
 	c.WithSchemes([]string{u.Scheme})
 
 	transport := httptransport.New(c.Host, c.BasePath, c.Schemes)
-	transport.Transport = newRoundTripper(transport.Transport)
+	transport.Transport, err = newRoundTripper(cfg, transport.Transport)
+	if err != nil {
+		return nil, err
+	}
 
-	authToken := retrieveToken(cfg)
+	authToken := RetrieveToken(cfg)
 	if authToken != "" {
 		transport.DefaultAuthentication = NewBearerToken(authToken)
 	}
@@ -206,9 +296,12 @@ func NewCatalog(cfg *config.Config) (*cClient.Catalog, error) {
 	c.WithSchemes([]string{u.Scheme})
 
 	transport := httptransport.New(c.Host, c.BasePath, c.Schemes)
-	transport.Transport = newRoundTripper(transport.Transport)
+	transport.Transport, err = newRoundTripper(cfg, transport.Transport)
+	if err != nil {
+		return nil, err
+	}
 
-	authToken := retrieveToken(cfg)
+	authToken := RetrieveToken(cfg)
 	if authToken != "" {
 		transport.DefaultAuthentication = NewBearerToken(authToken)
 	}
@@ -216,7 +309,10 @@ func NewCatalog(cfg *config.Config) (*cClient.Catalog, error) {
 	return cClient.New(transport, strfmt.Default), nil
 }
 
-func retrieveToken(cfg *config.Config) string {
+// RetrieveToken returns the token of the profile loaded into cfg, which
+// config.Load reads from the secret store of the profile. MANIFOLD_API_TOKEN
+// is used when the profile is logged out.
+func RetrieveToken(cfg *config.Config) string {
 	if cfg.AuthToken != "" {
 		return cfg.AuthToken
 	}
@@ -227,7 +323,32 @@ func retrieveToken(cfg *config.Config) string {
 	return ""
 }
 
+// deriveURL returns the url of a service on the host of the profile loaded
+// into cfg. Services of local hosts, such as a dev-server, are told apart by
+// path rather than subdomain.
 func deriveURL(cfg *config.Config, service string) (*url.URL, error) {
+	if isLocalHost(cfg.Hostname) {
+		u := fmt.Sprintf("%s://%s/%s/v1", cfg.TransportScheme, cfg.Hostname, service)
+		return url.Parse(u)
+	}
+
 	u := fmt.Sprintf("%s://api.%s.%s/v1", cfg.TransportScheme, service, cfg.Hostname)
 	return url.Parse(u)
 }
+
+// isLocalHost returns whether the hostname, with an optional port, is
+// localhost or a loopback address. Other IP addresses may reach real
+// deployments, which route services by subdomain.
+func isLocalHost(hostname string) bool {
+	host, _, err := net.SplitHostPort(hostname)
+	if err != nil {
+		host = hostname
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,109 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/manifoldco/go-manifold"
+
+	conClient "github.com/manifoldco/manifold-cli/generated/connector/client"
+)
+
+// OAuthCredential is an OAuth client pair used by a product to access the
+// Connector API. The secret is only known when the pair is created.
+type OAuthCredential struct {
+	ID          manifold.ID `json:"id"`
+	ProductID   manifold.ID `json:"product_id"`
+	Description string      `json:"description"`
+	Secret      string      `json:"secret,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// The connector spec defines the OAuth credential requests and responses
+// without their operations, so they are submitted directly.
+
+// FetchOAuthCredentials returns the OAuth credentials of a product.
+func FetchOAuthCredentials(ctx context.Context, c *conClient.Connector,
+	productID manifold.ID) ([]*OAuthCredential, error) {
+	var creds []*OAuthCredential
+	err := submitConnector(ctx, c, "GetCredentials", "GET", "/credentials",
+		func(r runtime.ClientRequest) error {
+			return r.SetQueryParam("product_id", productID.String())
+		}, http.StatusOK, &creds)
+	if err != nil {
+		return nil, err
+	}
+
+	return creds, nil
+}
+
+// CreateOAuthCredential creates an OAuth credential pair for a product. The
+// secret of the returned credential is not available afterwards.
+func CreateOAuthCredential(ctx context.Context, c *conClient.Connector, productID manifold.ID,
+	description string) (*OAuthCredential, error) {
+	req := struct {
+		ProductID   manifold.ID `json:"product_id"`
+		Description string      `json:"description"`
+	}{productID, description}
+
+	cred := &OAuthCredential{}
+	err := submitConnector(ctx, c, "PostCredentials", "POST", "/credentials",
+		func(r runtime.ClientRequest) error {
+			return r.SetBodyParam(req)
+		}, http.StatusCreated, cred)
+	if err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+// DeleteOAuthCredential deletes an OAuth credential pair, revoking its access.
+func DeleteOAuthCredential(ctx context.Context, c *conClient.Connector, id manifold.ID) error {
+	return submitConnector(ctx, c, "DeleteCredentialsID", "DELETE", "/credentials/{id}",
+		func(r runtime.ClientRequest) error {
+			return r.SetPathParam("id", id.String())
+		}, http.StatusNoContent, nil)
+}
+
+// submitConnector submits a request to the connector, decoding the response
+// into out when it has the expected status.
+func submitConnector(ctx context.Context, c *conClient.Connector, operation, method,
+	path string, params func(runtime.ClientRequest) error, status int, out interface{}) error {
+	_, err := c.Transport.Submit(&runtime.ClientOperation{
+		ID:                 operation,
+		Method:             method,
+		PathPattern:        path,
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Params: runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest,
+			_ strfmt.Registry) error {
+			return params(r)
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(r runtime.ClientResponse,
+			_ runtime.Consumer) (interface{}, error) {
+			b, err := ioutil.ReadAll(r.Body())
+			if err != nil {
+				return nil, err
+			}
+
+			if r.Code() != status {
+				return nil, apiError(operation, r.Code(), b)
+			}
+
+			if out == nil {
+				return nil, nil
+			}
+			return nil, json.Unmarshal(b, out)
+		}),
+		Context: ctx,
+	})
+
+	return err
+}
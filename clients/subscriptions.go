@@ -0,0 +1,72 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/manifoldco/go-manifold"
+
+	"github.com/manifoldco/manifold-cli/subscription"
+
+	bClient "github.com/manifoldco/manifold-cli/generated/billing/client"
+)
+
+// FetchSubscriptionEvents returns the credit events of the subscription event
+// log of the user, or of the team when one is given.
+//
+// The events are read as sent rather than through the generated models, as
+// their bodies are polymorphic and their signatures cover the exact fields.
+func FetchSubscriptionEvents(ctx context.Context, c *bClient.Billing,
+	teamID *manifold.ID) ([]subscription.Event, error) {
+	res, err := c.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "GetSubscriptionEvents",
+		Method:             "GET",
+		PathPattern:        "/subscription-events",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Params: runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest,
+			_ strfmt.Registry) error {
+			if err := r.SetQueryParam("event_type", subscription.CreditEvent); err != nil {
+				return err
+			}
+			if teamID != nil {
+				return r.SetQueryParam("team_id", teamID.String())
+			}
+			return nil
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(r runtime.ClientResponse,
+			_ runtime.Consumer) (interface{}, error) {
+			b, err := ioutil.ReadAll(r.Body())
+			if err != nil {
+				return nil, err
+			}
+
+			if r.Code() != http.StatusOK {
+				return nil, apiError("GetSubscriptionEvents", r.Code(), b)
+			}
+
+			return subscription.Decode(b)
+		}),
+		Context: ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.([]subscription.Event), nil
+}
+
+// apiError returns the error of a failed request submitted directly, rather
+// than through a generated client.
+func apiError(operation string, code int, b []byte) error {
+	apiErr := &manifold.Error{}
+	if err := json.Unmarshal(b, apiErr); err != nil || len(apiErr.Messages) == 0 {
+		return runtime.NewAPIError(operation, string(b), code)
+	}
+
+	return apiErr
+}
@@ -0,0 +1,620 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/juju/ansiterm"
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/bulk"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/converge"
+	catalogcache "github.com/manifoldco/manifold-cli/data/catalog"
+	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/prompts"
+	"github.com/manifoldco/manifold-cli/session"
+
+	cModels "github.com/manifoldco/manifold-cli/generated/catalog/models"
+	"github.com/manifoldco/manifold-cli/generated/marketplace/client/credential"
+	mModels "github.com/manifoldco/manifold-cli/generated/marketplace/models"
+)
+
+func init() {
+	pruneFlag := cli.BoolFlag{
+		Name:  "prune",
+		Usage: "Delete resources of the declared projects which are not declared",
+	}
+
+	planCmd := cli.Command{
+		Name:     "plan",
+		Usage:    "Show the changes needed to match the resources declared in .manifold.yml",
+		Category: "RESOURCES",
+		Flags:    append(teamFlags, pruneFlag),
+		Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
+			middleware.LoadTeamPrefs, planResourcesCmd),
+	}
+
+	applyCmd := cli.Command{
+		Name:     "apply",
+		Usage:    "Create, resize, move or delete resources to match .manifold.yml",
+		Category: "RESOURCES",
+		Flags: append(teamFlags, pruneFlag, yesFlag(), cli.IntFlag{
+			Name:  "concurrency",
+			Usage: "Apply changes to at most this many resources at the same time",
+			Value: bulk.DefaultWorkers,
+		}),
+		Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
+			middleware.LoadTeamPrefs, applyResourcesCmd),
+	}
+
+	cmds = append(cmds, planCmd, applyCmd)
+}
+
+// applyPlan holds the changes to apply, along with the data needed to
+// apply them.
+type applyPlan struct {
+	changes   []converge.Change
+	resources map[string]*mModels.Resource
+	projects  map[string]*mModels.Project
+	catalog   *catalogcache.Catalog
+}
+
+func planResourcesCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	teamID, err := validateTeamID(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Catalog, api.Marketplace)
+	if err != nil {
+		return err
+	}
+
+	plan, err := loadResourcePlan(ctx, cliCtx, client, teamID)
+	if err != nil {
+		return err
+	}
+
+	writeResourcePlan(plan.changes)
+	return nil
+}
+
+func applyResourcesCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	teamID, err := validateTeamID(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	userID, userIDErr := loadUserID(ctx)
+	if userIDErr != nil && userIDErr != errUserActionAsTeam {
+		return userIDErr
+	}
+	if teamID == nil && userIDErr == errUserActionAsTeam {
+		return errUserActionAsTeam
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return cli.NewExitError("Could not load config: "+err.Error(), -1)
+	}
+
+	s, err := session.Retrieve(ctx, cfg)
+	if err != nil {
+		return cli.NewExitError("Could not retrieve session: "+err.Error(), -1)
+	}
+
+	client, err := api.New(api.Analytics, api.Catalog, api.Marketplace, api.Provisioning)
+	if err != nil {
+		return err
+	}
+
+	plan, err := loadResourcePlan(ctx, cliCtx, client, teamID)
+	if err != nil {
+		return err
+	}
+
+	writeResourcePlan(plan.changes)
+	if len(plan.changes) == 0 {
+		return nil
+	}
+
+	if !cliCtx.Bool("yes") {
+		msg := "Apply these changes"
+		if n := countChanges(plan.changes, converge.Delete); n > 0 {
+			msg = fmt.Sprintf("Apply these changes, deleting %d resource(s)", n)
+		}
+
+		_, err = prompts.Confirm(msg, "--yes")
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("")
+
+	// Changes to the same resource are applied in order, other resources are
+	// changed concurrently
+	tasks := make([]bulk.Task, 0, len(plan.changes))
+	previous := make(map[string]string)
+	for _, c := range plan.changes {
+		c := c
+		t := bulk.Task{
+			Resource: c.Name,
+			Action:   string(c.Action),
+			Run: func(ctx context.Context, report func(string)) error {
+				ctx = withOpReporter(ctx, report)
+				switch c.Action {
+				case converge.Create:
+					return plan.create(ctx, cfg, s, client, teamID, c)
+				case converge.Resize:
+					return plan.resize(ctx, client, teamID, userID, c)
+				case converge.Move:
+					return updateResourceProject(ctx, userID, teamID, plan.resources[c.Name],
+						plan.projects[c.Desired.Project], client.Provisioning, false)
+				case converge.Update:
+					return updateResourceValues(ctx, client, plan.resources[c.Name], c.Aliases, c.Config)
+				case converge.Delete:
+					return deleteResource(ctx, cfg, teamID, s, plan.resources[c.Name], client.Provisioning, false)
+				}
+				return nil
+			},
+		}
+
+		if key, ok := previous[c.Name]; ok {
+			t.DependsOn = []string{key}
+		}
+		previous[c.Name] = c.Name + "/" + string(c.Action)
+
+		tasks = append(tasks, t)
+	}
+
+	if err := runOperations(ctx, tasks, cliCtx.Int("concurrency")); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nYour resources match %s\n", config.YamlFilename)
+	return nil
+}
+
+// loadResourcePlan compares the resources declared in .manifold.yml with the
+// resources of the team.
+func loadResourcePlan(ctx context.Context, cliCtx *cli.Context, client *api.API,
+	teamID *manifold.ID) (*applyPlan, error) {
+	yml, err := config.LoadYaml(true)
+	if err != nil {
+		return nil, cli.NewExitError("Could not load "+config.YamlFilename+": "+err.Error(), -1)
+	}
+
+	if len(yml.Resources) == 0 {
+		return nil, cli.NewExitError("No resources are declared in "+config.YamlFilename, -1)
+	}
+
+	prompts.SpinStart("Fetching Resources")
+	defer prompts.SpinStop()
+
+	catalog, err := catalogcache.New(ctx, client.Catalog)
+	if err != nil {
+		return nil, cli.NewExitError("Failed to fetch catalog data: "+err.Error(), -1)
+	}
+
+	resources, err := clients.FetchResources(ctx, client.Marketplace, teamID, "")
+	if err != nil {
+		return nil, cli.NewExitError("Could not retrieve resources: "+err.Error(), -1)
+	}
+
+	projects, err := clients.FetchProjects(ctx, client.Marketplace, teamID)
+	if err != nil {
+		return nil, cli.NewExitError("Could not retrieve projects: "+err.Error(), -1)
+	}
+
+	plan := &applyPlan{
+		resources: make(map[string]*mModels.Resource),
+		projects:  make(map[string]*mModels.Project),
+		catalog:   catalog,
+	}
+
+	projectLabels := make(map[manifold.ID]string)
+	for _, p := range projects {
+		plan.projects[string(p.Body.Label)] = p
+		projectLabels[p.ID] = string(p.Body.Label)
+	}
+
+	desired := make([]converge.State, len(yml.Resources))
+	needsValues := make(map[string]bool)
+	for i, spec := range yml.Resources {
+		d, err := plan.desiredState(spec, yml.Project)
+		if err != nil {
+			return nil, cli.NewExitError(err.Error(), -1)
+		}
+
+		desired[i] = *d
+		needsValues[d.Name] = len(d.Aliases) > 0 || len(d.Config) > 0
+	}
+
+	current := make([]converge.State, 0, len(resources))
+	var withValues []*mModels.Resource
+	for _, r := range resources {
+		c, err := plan.currentState(ctx, r, projectLabels)
+		if err != nil {
+			return nil, err
+		}
+
+		plan.resources[c.Name] = r
+		current = append(current, *c)
+
+		if needsValues[c.Name] {
+			withValues = append(withValues, r)
+		}
+	}
+
+	// Aliases and config values are only fetched for the resources declaring
+	// some, as they require a credentials lookup.
+	if len(withValues) > 0 {
+		cMap, err := fetchResourceCredentials(ctx, client.Marketplace, withValues, false)
+		if err != nil {
+			return nil, cli.NewExitError("Could not retrieve credentials: "+err.Error(), -1)
+		}
+
+		for i := range current {
+			r := plan.resources[current[i].Name]
+			for _, cred := range cMap[r.ID] {
+				current[i].Aliases = mergeValues(current[i].Aliases, cred.Body.CustomNames)
+				if current[i].Custom() {
+					current[i].Config = mergeValues(current[i].Config, cred.Body.Values)
+				}
+			}
+		}
+	}
+
+	plan.changes, err = converge.Plan(desired, current, cliCtx.Bool("prune"))
+	if err != nil {
+		return nil, cli.NewExitError(err.Error(), -1)
+	}
+
+	return plan, nil
+}
+
+// desiredState validates a declared resource against the catalog and the
+// team's projects.
+func (p *applyPlan) desiredState(spec config.ResourceSpec, project string) (*converge.State, error) {
+	d := &converge.State{
+		Name:    spec.Name,
+		Title:   spec.Title,
+		Product: spec.Product,
+		Plan:    spec.Plan,
+		Project: spec.Project,
+		Aliases: spec.Aliases,
+		Config:  spec.Config,
+	}
+
+	if d.Project == "" {
+		d.Project = project
+	}
+	if d.Project != "" && p.projects[d.Project] == nil {
+		return nil, fmt.Errorf("Project %q of resource %q does not exist", d.Project, d.Name)
+	}
+
+	if d.Product != "" && p.product(d.Product) == nil {
+		return nil, fmt.Errorf("Product %q of resource %q does not exist", d.Product, d.Name)
+	}
+
+	if spec.Region != "" {
+		r := p.region(spec.Region)
+		if r == nil {
+			return nil, fmt.Errorf("Region %q of resource %q does not exist", spec.Region, d.Name)
+		}
+		d.Region = regionKey(r)
+	}
+
+	return d, nil
+}
+
+// currentState describes an existing resource using labels, so it can be
+// compared with the declared resources. Plans missing from the catalog, such
+// as unlisted ones, are fetched like `list` does.
+func (p *applyPlan) currentState(ctx context.Context, r *mModels.Resource,
+	projectLabels map[manifold.ID]string) (*converge.State, error) {
+	c := &converge.State{
+		Name:  string(r.Body.Label),
+		Title: string(r.Body.Name),
+	}
+
+	if r.Body.ProjectID != nil {
+		c.Project = projectLabels[*r.Body.ProjectID]
+	}
+
+	if r.Body.Source != nil && *r.Body.Source == "custom" {
+		return c, nil
+	}
+
+	if r.Body.ProductID != nil && r.Body.PlanID != nil {
+		product, plan, err := resourcePlan(ctx, p.catalog, r)
+		if err != nil {
+			return nil, err
+		}
+		c.Product = string(product.Body.Label)
+		c.Plan = string(plan.Body.Label)
+	}
+
+	if r.Body.RegionID != nil {
+		region, err := p.catalog.GetRegion(*r.Body.RegionID)
+		if err != nil {
+			return nil, cli.NewExitError(fmt.Sprintf("Region of resource %q not found: %s", c.Name, err), -1)
+		}
+		c.Region = regionKey(region)
+	}
+
+	return c, nil
+}
+
+func (p *applyPlan) create(ctx context.Context, cfg *config.Config, s session.Session,
+	client *api.API, teamID *manifold.ID, c converge.Change) error {
+	var product *cModels.Product
+	var plan *cModels.Plan
+	var region *cModels.Region
+
+	custom := c.Desired.Custom()
+	if !custom {
+		if c.Desired.Plan == "" {
+			return fmt.Errorf("a plan is required")
+		}
+
+		product = p.product(c.Desired.Product)
+
+		var err error
+		plan, err = p.catalog.FetchPlanByLabel(ctx, product.ID, c.Desired.Plan)
+		if err != nil {
+			return err
+		}
+
+		regions := filterRegionsForPlan(p.catalog.Regions(), plan.Body.Regions)
+		for _, r := range regions {
+			if (c.Desired.Region == "" && len(regions) == 1) || regionKey(r) == c.Desired.Region {
+				region = r
+			}
+		}
+		if region == nil {
+			return fmt.Errorf("a region supported by plan %q is required", c.Desired.Plan)
+		}
+	}
+
+	resourceID, err := manifold.NewID(idtype.Resource)
+	if err != nil {
+		return err
+	}
+
+	title := c.Desired.Title
+	if title == "" {
+		title = c.Name
+	}
+
+	_, err = createResource(ctx, cfg, &resourceID, teamID, s, client.Provisioning, custom,
+		product, plan, region, p.projects[c.Desired.Project], c.Name, title, false)
+	if err != nil {
+		return err
+	}
+
+	if len(c.Desired.Aliases) == 0 && len(c.Desired.Config) == 0 {
+		return nil
+	}
+
+	r := &mModels.Resource{ID: resourceID}
+	return updateResourceValues(ctx, client, r, c.Desired.Aliases, c.Desired.Config)
+}
+
+func (p *applyPlan) resize(ctx context.Context, client *api.API, teamID, userID *manifold.ID,
+	c converge.Change) error {
+	product := p.product(c.Desired.Product)
+
+	plan, err := p.catalog.FetchPlanByLabel(ctx, product.ID, c.Desired.Plan)
+	if err != nil {
+		return err
+	}
+
+	return resizeResource(ctx, p.resources[c.Name], plan, client, teamID, userID, false)
+}
+
+func (p *applyPlan) product(label string) *cModels.Product {
+	for _, product := range p.catalog.Products() {
+		if string(product.Body.Label) == label {
+			return product
+		}
+	}
+
+	return nil
+}
+
+// region finds a region by its name, or by its platform::location key.
+func (p *applyPlan) region(name string) *cModels.Region {
+	regions := filterRegionsByName(p.catalog.Regions(), name)
+	if len(regions) == 0 {
+		return nil
+	}
+
+	return regions[0]
+}
+
+// updateResourceValues sets credential aliases and custom config values.
+func updateResourceValues(ctx context.Context, client *api.API, r *mModels.Resource,
+	aliases, values map[string]string) error {
+	if len(values) > 0 {
+		body := make(map[string]*string)
+		for k := range values {
+			v := values[k]
+			body[k] = &v
+		}
+
+		_, err := client.Marketplace.Credential.PatchResourcesIDConfig(&credential.PatchResourcesIDConfigParams{
+			ID:      r.ID.String(),
+			Body:    body,
+			Context: ctx,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	cMap, err := fetchResourceCredentials(ctx, client.Marketplace, []*mModels.Resource{r}, false)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool)
+	for _, cred := range cMap[r.ID] {
+		alias := make(map[string]string)
+		for original, name := range aliases {
+			if _, ok := cred.Body.Values[original]; ok {
+				alias[original] = name
+				found[original] = true
+			}
+		}
+
+		if len(alias) == 0 {
+			continue
+		}
+
+		params := credential.NewPatchCredentialsIDParamsWithContext(ctx)
+		params.SetID(cred.ID.String())
+		params.SetBody(&mModels.UpdateCredential{
+			Body: &mModels.UpdateCredentialBody{
+				CustomNames: alias,
+			},
+		})
+		if _, err := client.Marketplace.Credential.PatchCredentialsID(params, nil); err != nil {
+			return err
+		}
+	}
+
+	for original := range aliases {
+		if !found[original] {
+			return fmt.Errorf("credential %q not found", original)
+		}
+	}
+
+	return nil
+}
+
+func mergeValues(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string)
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+
+	return dst
+}
+
+func countChanges(changes []converge.Change, action converge.Action) int {
+	n := 0
+	for _, c := range changes {
+		if c.Action == action {
+			n++
+		}
+	}
+
+	return n
+}
+
+// writeResourcePlan prints the changes of a plan. Config values are never
+// printed, only their keys.
+func writeResourcePlan(changes []converge.Change) {
+	if len(changes) == 0 {
+		fmt.Printf("No changes, your resources match %s\n", config.YamlFilename)
+		return
+	}
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	for _, c := range changes {
+		var symbol, details string
+
+		switch c.Action {
+		case converge.Create:
+			symbol = color.Color(ansiterm.Green, "+")
+			details = "custom resource"
+			if !c.Desired.Custom() {
+				details = c.Desired.Product + "/" + c.Desired.Plan
+				if c.Desired.Region != "" {
+					details += " in " + c.Desired.Region
+				}
+			}
+			if c.Desired.Project != "" {
+				details += ", project " + c.Desired.Project
+			}
+		case converge.Resize:
+			symbol = color.Color(ansiterm.Yellow, "~")
+			details = c.Current.Plan + " -> " + c.Desired.Plan
+		case converge.Move:
+			symbol = color.Color(ansiterm.Yellow, "~")
+			details = projectOrNone(c.Current.Project) + " -> " + projectOrNone(c.Desired.Project)
+		case converge.Update:
+			symbol = color.Color(ansiterm.Yellow, "~")
+			var parts []string
+			if len(c.Aliases) > 0 {
+				parts = append(parts, "aliases "+strings.Join(sortedKeys(c.Aliases), ", "))
+			}
+			if len(c.Config) > 0 {
+				parts = append(parts, "config "+strings.Join(sortedKeys(c.Config), ", "))
+			}
+			details = strings.Join(parts, "; ")
+		case converge.Delete:
+			symbol = color.Color(ansiterm.Red, "-")
+		}
+
+		fmt.Fprintf(w, "%s %s\t%s\t%s\n", symbol, c.Action, color.Bold(c.Name), color.Faint(details))
+	}
+
+	w.Flush()
+
+	creates := countChanges(changes, converge.Create)
+	deletes := countChanges(changes, converge.Delete)
+	fmt.Printf("\nPlan: %d to create, %d to change, %d to delete.\n",
+		creates, len(changes)-creates-deletes, deletes)
+}
+
+func projectOrNone(project string) string {
+	if project == "" {
+		return "(no project)"
+	}
+
+	return project
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/activity"
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/audit"
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/prompts"
+)
+
+// auditMarksFilename stores the high-water marks of incremental exports. It
+// lives outside of ~/.manifoldcache so `cache clear` doesn't reset them.
+const auditMarksFilename = ".manifoldaudit"
+
+func eventsExportFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "file",
+			Usage: "Append events to this file instead of writing them to stdout",
+		},
+		cli.BoolFlag{
+			Name:  "csv",
+			Usage: "Write events as CSV instead of JSON Lines",
+		},
+		cli.BoolFlag{
+			Name:  "syslog",
+			Usage: "Send events as RFC 5424 messages to the local syslog daemon",
+		},
+		cli.StringFlag{
+			Name:  "syslog-socket",
+			Usage: "Path of the syslog socket, /dev/log or the platform default otherwise",
+		},
+		cli.BoolFlag{
+			Name:  "incremental",
+			Usage: "Only export events newer than the last one exported to the same destination, without filters",
+		},
+		cli.StringFlag{
+			Name:  "state-file",
+			Usage: "Where incremental exports keep the last event exported, ~/" + auditMarksFilename + " by default",
+		},
+	}
+}
+
+func eventsExport(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	// A single event is remembered per destination, so a filtered export
+	// would make later ones skip the events it left out
+	if cliCtx.Bool("incremental") && eventFiltered(cliCtx) {
+		return cli.NewExitError("--incremental can't be used with event filters", -1)
+	}
+
+	file := cliCtx.String("file")
+	toSyslog := cliCtx.Bool("syslog")
+	if toSyslog && (file != "" || cliCtx.Bool("csv")) {
+		return cli.NewExitError("--syslog can't be used with --file or --csv", -1)
+	}
+
+	destination := "stdout"
+	switch {
+	case toSyslog:
+		destination = "syslog:" + cliCtx.String("syslog-socket")
+	case file != "":
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return cli.NewExitError("Invalid file: "+err.Error(), -1)
+		}
+		destination = abs
+	}
+
+	scopeID, teamID, err := eventScope(ctx, cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Activity, api.Identity, api.Marketplace)
+	if err != nil {
+		return err
+	}
+
+	filter, err := eventFilter(ctx, cliCtx, client, teamID)
+	if err != nil {
+		return err
+	}
+
+	prompts.SpinStart("Fetching Activities")
+	evts, err := client.Events(scopeID)
+	prompts.SpinStop()
+	if err != nil {
+		return cli.NewExitError("Could not retrieve events: "+err.Error(), -1)
+	}
+
+	evts = filter.Apply(evts)
+	activity.Sort(evts)
+
+	records := make([]audit.Record, 0, len(evts))
+	for _, e := range evts {
+		records = append(records, audit.NewRecord(e))
+	}
+
+	incremental := cliCtx.Bool("incremental")
+	var marksPath, key string
+	var marks map[string]audit.Mark
+	if incremental {
+		marksPath, err = auditMarksPath(cliCtx)
+		if err != nil {
+			return err
+		}
+
+		marks, err = audit.LoadMarks(marksPath)
+		if err != nil {
+			return cli.NewExitError("Could not read the last exported events: "+err.Error(), -1)
+		}
+
+		key = audit.MarkKey(scopeID.String(), destination)
+		if m, ok := marks[key]; ok {
+			records = audit.After(records, &m)
+		}
+	}
+
+	w, closer, err := auditWriter(cliCtx, toSyslog, file, incremental)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			return cli.NewExitError("Could not export events: "+err.Error(), -1)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return cli.NewExitError("Could not export events: "+err.Error(), -1)
+	}
+	if err := closer.Close(); err != nil {
+		return cli.NewExitError("Could not export events: "+err.Error(), -1)
+	}
+
+	if incremental && len(records) > 0 {
+		marks[key] = audit.MarkOf(records[len(records)-1])
+		if err := audit.SaveMarks(marksPath, marks); err != nil {
+			return cli.NewExitError("Could not save the last exported event: "+err.Error(), -1)
+		}
+	}
+
+	// Keep stdout clean when events are written to it
+	if toSyslog || file != "" {
+		fmt.Printf("Exported %d events to %s\n", len(records), destination)
+	} else {
+		fmt.Fprintf(os.Stderr, "Exported %d events\n", len(records))
+	}
+
+	return nil
+}
+
+// auditWriter opens the destination of an export. Files are appended to by
+// incremental exports and replaced otherwise, the CSV header being written
+// to empty files only.
+func auditWriter(cliCtx *cli.Context, toSyslog bool, file string,
+	incremental bool) (audit.Writer, io.Closer, error) {
+	if toSyslog {
+		conn, err := audit.DialSyslog(cliCtx.String("syslog-socket"))
+		if err != nil {
+			return nil, nil, cli.NewExitError("Could not connect to syslog: "+err.Error(), -1)
+		}
+		return audit.NewSyslog(conn, config.Version), &onceCloser{c: conn}, nil
+	}
+
+	var out io.Writer = os.Stdout
+	var closer io.Closer = &onceCloser{}
+	header := true
+
+	if file != "" {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if incremental {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+
+		f, err := os.OpenFile(file, flags, 0600)
+		if err != nil {
+			return nil, nil, cli.NewExitError("Could not open file: "+err.Error(), -1)
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, nil, cli.NewExitError("Could not open file: "+err.Error(), -1)
+		}
+
+		out, closer, header = f, &onceCloser{c: f}, info.Size() == 0
+	}
+
+	if cliCtx.Bool("csv") {
+		return audit.NewCSV(out, header), closer, nil
+	}
+
+	return audit.NewJSONLines(out), closer, nil
+}
+
+func auditMarksPath(cliCtx *cli.Context) (string, error) {
+	if p := cliCtx.String("state-file"); p != "" {
+		return p, nil
+	}
+
+	home, err := config.UserHome()
+	if err != nil {
+		return "", cli.NewExitError("Could not find your home directory: "+err.Error(), -1)
+	}
+
+	return filepath.Join(home, auditMarksFilename), nil
+}
+
+// onceCloser closes c on the first call only, so the destination can both be
+// closed explicitly to catch errors and deferred.
+type onceCloser struct {
+	c      io.Closer
+	closed bool
+}
+
+func (o *onceCloser) Close() error {
+	if o.c == nil || o.closed {
+		return nil
+	}
+
+	o.closed = true
+	return o.c.Close()
+}
@@ -48,6 +48,20 @@ func init() {
 				Action: middleware.Chain(middleware.EnsureSession,
 					middleware.LoadTeamPrefs, redeemCouponCmd),
 			},
+			{
+				Name:  "credits",
+				Usage: "List applied coupons and credits with their remaining balance",
+				Flags: append(teamFlags, outputFlag()),
+				Action: middleware.Chain(middleware.EnsureSession,
+					middleware.LoadTeamPrefs, creditsCmd),
+			},
+			{
+				Name:  "verify",
+				Usage: "Verify the signatures and chaining of your subscription event log",
+				Flags: append(teamFlags, outputFlag()),
+				Action: middleware.Chain(middleware.EnsureSession,
+					middleware.LoadTeamPrefs, verifyEventsCmd),
+			},
 		},
 	}
 
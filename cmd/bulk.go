@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/juju/ansiterm"
+	"github.com/manifoldco/go-manifold"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/bulk"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/config"
+	catalogcache "github.com/manifoldco/manifold-cli/data/catalog"
+	"github.com/manifoldco/manifold-cli/errs"
+	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/prompts"
+	"github.com/manifoldco/manifold-cli/session"
+
+	cModels "github.com/manifoldco/manifold-cli/generated/catalog/models"
+	mModels "github.com/manifoldco/manifold-cli/generated/marketplace/models"
+)
+
+func init() {
+	bulkCmd := cli.Command{
+		Name:     "bulk",
+		Usage:    "Resize, move or delete many resources at once",
+		Category: "RESOURCES",
+		Subcommands: []cli.Command{
+			{
+				Name:  "resize",
+				Usage: "Resize the selected resources to a plan",
+				Flags: bulkFlags(planFlag()),
+				Action: middleware.Chain(middleware.EnsureSession, middleware.LoadTeamPrefs,
+					bulkResizeCmd),
+			},
+			{
+				Name:  "move",
+				Usage: "Move the selected resources into a project",
+				Flags: bulkFlags(cli.StringFlag{
+					Name:  "to",
+					Usage: "Move the resources into this project",
+				}),
+				Action: middleware.Chain(middleware.EnsureSession, middleware.LoadTeamPrefs,
+					bulkMoveCmd),
+			},
+			{
+				Name:  "delete",
+				Usage: "Delete the selected resources",
+				Flags: bulkFlags(),
+				Action: middleware.Chain(middleware.EnsureSession, middleware.LoadTeamPrefs,
+					bulkDeleteCmd),
+			},
+		},
+	}
+
+	cmds = append(cmds, bulkCmd)
+}
+
+// bulkFlags returns the flags selecting resources, along with the given
+// flags.
+func bulkFlags(flags ...cli.Flag) []cli.Flag {
+	out := append([]cli.Flag{}, teamFlags...)
+	out = append(out,
+		cli.StringSliceFlag{
+			Name:  "project",
+			Usage: "Select the resources of this project, can be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  "product",
+			Usage: "Select the resources of this product, can be repeated",
+		},
+		cli.StringFlag{
+			Name:  "match",
+			Usage: "Select the resources with a name matching this glob, such as 'cache-*'",
+		},
+		cli.IntFlag{
+			Name:  "concurrency",
+			Usage: "Run at most this many operations at the same time",
+			Value: bulk.DefaultWorkers,
+		},
+		yesFlag(),
+	)
+
+	return append(out, flags...)
+}
+
+// bulkTarget holds the selected resources along with the data needed to
+// operate on them.
+type bulkTarget struct {
+	teamID    *manifold.ID
+	userID    *manifold.ID
+	client    *api.API
+	catalog   *catalogcache.Catalog
+	projects  []*mModels.Project
+	resources []*mModels.Resource
+}
+
+func bulkResizeCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	planLabel := cliCtx.String("plan")
+	if planLabel == "" {
+		return errs.NewUsageExitError(cliCtx, cli.NewExitError("--plan is required", -1))
+	}
+
+	t, err := loadBulkTarget(ctx, cliCtx)
+	if err != nil {
+		return err
+	}
+
+	plans := make(map[manifold.ID]*cModels.Plan)
+	tasks := make([]bulk.Task, 0, len(t.resources))
+	for _, r := range t.resources {
+		if r.Body.ProductID == nil {
+			return cli.NewExitError(fmt.Sprintf("Cannot resize custom resource %q", r.Body.Label), -1)
+		}
+
+		plan, ok := plans[*r.Body.ProductID]
+		if !ok {
+			plan, err = t.catalog.FetchPlanByLabel(ctx, *r.Body.ProductID, planLabel)
+			if err != nil {
+				return cli.NewExitError(fmt.Sprintf("Plan %q is not available for resource %q: %s",
+					planLabel, r.Body.Label, err), -1)
+			}
+			plans[*r.Body.ProductID] = plan
+		}
+
+		if r.Body.PlanID != nil && *r.Body.PlanID == plan.ID {
+			continue
+		}
+
+		r := r
+		tasks = append(tasks, bulk.Task{
+			Resource: string(r.Body.Label),
+			Action:   "resize",
+			Run: func(ctx context.Context, report func(string)) error {
+				return resizeResource(withOpReporter(ctx, report), r, plan, t.client,
+					t.teamID, t.userID, false)
+			},
+		})
+	}
+
+	return confirmAndRun(ctx, cliCtx, tasks, fmt.Sprintf("Resize %d resource(s) to %q", len(tasks), planLabel))
+}
+
+func bulkMoveCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	to := cliCtx.String("to")
+	if to == "" {
+		return errs.NewUsageExitError(cliCtx, cli.NewExitError("--to is required", -1))
+	}
+
+	t, err := loadBulkTarget(ctx, cliCtx)
+	if err != nil {
+		return err
+	}
+
+	var project *mModels.Project
+	for _, p := range t.projects {
+		if string(p.Body.Label) == to {
+			project = p
+		}
+	}
+	if project == nil {
+		return errs.ErrProjectNotFound
+	}
+
+	tasks := make([]bulk.Task, 0, len(t.resources))
+	for _, r := range t.resources {
+		if r.Body.ProjectID != nil && *r.Body.ProjectID == project.ID {
+			continue
+		}
+
+		r := r
+		tasks = append(tasks, bulk.Task{
+			Resource: string(r.Body.Label),
+			Action:   "move",
+			Run: func(ctx context.Context, report func(string)) error {
+				return updateResourceProject(withOpReporter(ctx, report), t.userID, t.teamID, r,
+					project, t.client.Provisioning, false)
+			},
+		})
+	}
+
+	return confirmAndRun(ctx, cliCtx, tasks, fmt.Sprintf("Move %d resource(s) into %q", len(tasks), to))
+}
+
+func bulkDeleteCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	t, err := loadBulkTarget(ctx, cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return cli.NewExitError("Could not load config: "+err.Error(), -1)
+	}
+
+	s, err := session.Retrieve(ctx, cfg)
+	if err != nil {
+		return cli.NewExitError("Could not retrieve session: "+err.Error(), -1)
+	}
+
+	tasks := make([]bulk.Task, 0, len(t.resources))
+	for _, r := range t.resources {
+		r := r
+		tasks = append(tasks, bulk.Task{
+			Resource: string(r.Body.Label),
+			Action:   "delete",
+			Run: func(ctx context.Context, report func(string)) error {
+				return deleteResource(withOpReporter(ctx, report), cfg, t.teamID, s, r,
+					t.client.Provisioning, false)
+			},
+		})
+	}
+
+	return confirmAndRun(ctx, cliCtx, tasks, fmt.Sprintf("Delete %d resource(s)", len(tasks)))
+}
+
+// loadBulkTarget selects resources by project, product and name. At least
+// one selector is required so a typo never selects every resource.
+func loadBulkTarget(ctx context.Context, cliCtx *cli.Context) (*bulkTarget, error) {
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return nil, err
+	}
+
+	projectLabels := cliCtx.StringSlice("project")
+	productLabels := cliCtx.StringSlice("product")
+	match := cliCtx.String("match")
+	if len(projectLabels) == 0 && len(productLabels) == 0 && match == "" {
+		return nil, errs.NewUsageExitError(cliCtx, cli.NewExitError(
+			"Select resources with at least one of --project, --product or --match", -1))
+	}
+	if _, err := path.Match(match, ""); err != nil {
+		return nil, errs.NewUsageExitError(cliCtx, cli.NewExitError("Invalid --match pattern", -1))
+	}
+
+	teamID, err := validateTeamID(cliCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, userIDErr := loadUserID(ctx)
+	if userIDErr != nil && userIDErr != errUserActionAsTeam {
+		return nil, userIDErr
+	}
+	if teamID == nil && userIDErr == errUserActionAsTeam {
+		return nil, errUserActionAsTeam
+	}
+
+	client, err := api.New(api.Analytics, api.Catalog, api.Marketplace, api.Provisioning)
+	if err != nil {
+		return nil, err
+	}
+
+	prompts.SpinStart("Fetching Resources")
+	defer prompts.SpinStop()
+
+	catalog, err := catalogcache.New(ctx, client.Catalog)
+	if err != nil {
+		return nil, cli.NewExitError("Could not load catalog: "+err.Error(), -1)
+	}
+
+	projects, err := clients.FetchProjects(ctx, client.Marketplace, teamID)
+	if err != nil {
+		return nil, cli.NewExitError("Could not load projects: "+err.Error(), -1)
+	}
+
+	resources, err := clients.FetchResources(ctx, client.Marketplace, teamID, "")
+	if err != nil {
+		return nil, cli.NewExitError("Could not load resources: "+err.Error(), -1)
+	}
+
+	projectIDs := make(map[manifold.ID]bool)
+	for _, label := range projectLabels {
+		found := false
+		for _, p := range projects {
+			if string(p.Body.Label) == label {
+				projectIDs[p.ID] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, cli.NewExitError(fmt.Sprintf("Project %q not found", label), -1)
+		}
+	}
+
+	productIDs := make(map[manifold.ID]bool)
+	for _, label := range productLabels {
+		found := false
+		for _, p := range catalog.Products() {
+			if string(p.Body.Label) == label {
+				productIDs[p.ID] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, cli.NewExitError(fmt.Sprintf("Product %q not found", label), -1)
+		}
+	}
+
+	var selected []*mModels.Resource
+	for _, r := range resources {
+		if len(projectIDs) > 0 && (r.Body.ProjectID == nil || !projectIDs[*r.Body.ProjectID]) {
+			continue
+		}
+		if len(productIDs) > 0 && (r.Body.ProductID == nil || !productIDs[*r.Body.ProductID]) {
+			continue
+		}
+		if ok, _ := path.Match(match, string(r.Body.Label)); match != "" && !ok {
+			continue
+		}
+
+		selected = append(selected, r)
+	}
+
+	if len(selected) == 0 {
+		return nil, errs.ErrNoResources
+	}
+
+	return &bulkTarget{
+		teamID:    teamID,
+		userID:    userID,
+		client:    client,
+		catalog:   catalog,
+		projects:  projects,
+		resources: selected,
+	}, nil
+}
+
+// confirmAndRun lists the operations and asks for confirmation unless --yes
+// is set, before running them.
+func confirmAndRun(ctx context.Context, cliCtx *cli.Context, tasks []bulk.Task, msg string) error {
+	if len(tasks) == 0 {
+		fmt.Println("Every selected resource is already up to date.")
+		return nil
+	}
+
+	for _, t := range tasks {
+		fmt.Printf("  %s %s\n", t.Action, t.Resource)
+	}
+	fmt.Println("")
+
+	if !cliCtx.Bool("yes") {
+		if _, err := prompts.Confirm(msg, "--yes"); err != nil {
+			return err
+		}
+	}
+
+	return runOperations(ctx, tasks, cliCtx.Int("concurrency"))
+}
+
+// runOperations applies the tasks concurrently while rendering their
+// progress, then summarizes the result of every operation.
+func runOperations(ctx context.Context, tasks []bulk.Task, workers int) error {
+	progress := bulk.NewProgress(os.Stdout, prompts.IsInteractive)
+
+	results, err := bulk.Run(ctx, tasks, workers, progress.Update)
+	if err != nil {
+		return cli.NewExitError("Could not run operations: "+err.Error(), -1)
+	}
+
+	failed := bulk.Count(results, bulk.Failed) + bulk.Count(results, bulk.Skipped)
+	if failed == 0 {
+		fmt.Printf("\n%s %d operation(s) completed\n", color.Color(ansiterm.Green, "✔"), len(results))
+		return nil
+	}
+
+	fmt.Printf("\n%d of %d operation(s) did not complete:\n", failed, len(results))
+	for _, r := range results {
+		if r.Status == bulk.Failed || r.Status == bulk.Skipped {
+			fmt.Println("  " + strings.TrimSpace(bulk.Line(r)))
+		}
+	}
+
+	return cli.NewExitError(fmt.Sprintf("%d operation(s) failed", failed), -1)
+}
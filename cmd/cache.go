@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/credcache"
+
+	"github.com/manifoldco/manifold-cli/generated/marketplace/models"
+)
+
+func init() {
+	cacheCmd := cli.Command{
+		Name:     "cache",
+		Usage:    "Manage the encrypted offline credential cache",
+		Category: "CONFIGURATION",
+		Subcommands: []cli.Command{
+			{
+				Name:  "enable",
+				Usage: "Cache credentials fetched by run and export for use with --offline",
+				Flags: []cli.Flag{
+					cli.DurationFlag{
+						Name:  "ttl",
+						Usage: "How long cached credentials can be served",
+						Value: credcache.DefaultTTL,
+					},
+				},
+				Action: enableCacheCmd,
+			},
+			{
+				Name:   "disable",
+				Usage:  "Stop caching credentials and remove the cache",
+				Action: disableCacheCmd,
+			},
+			{
+				Name:   "clear",
+				Usage:  "Remove all cached credentials",
+				Action: clearCacheCmd,
+			},
+		},
+	}
+
+	cmds = append(cmds, cacheCmd)
+}
+
+func enableCacheCmd(cliCtx *cli.Context) error {
+	ttl := cliCtx.Duration("ttl")
+	if ttl <= 0 {
+		return cli.NewExitError("--ttl must be greater than zero", -1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return cli.NewExitError("Could not load config: "+err.Error(), -1)
+	}
+
+	cfg.CredentialCache = true
+	cfg.CredentialCacheTTL = ttl.String()
+	if err := cfg.Write(); err != nil {
+		return cli.NewExitError("Could not save config: "+err.Error(), -1)
+	}
+
+	fmt.Printf("Credentials will be cached for %s.\n", ttl)
+	return nil
+}
+
+func disableCacheCmd(cliCtx *cli.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return cli.NewExitError("Could not load config: "+err.Error(), -1)
+	}
+
+	cfg.CredentialCache = false
+	cfg.CredentialCacheTTL = ""
+	if err := cfg.Write(); err != nil {
+		return cli.NewExitError("Could not save config: "+err.Error(), -1)
+	}
+
+	if err := clearCache(cfg); err != nil {
+		return err
+	}
+
+	fmt.Println("Credential caching is disabled.")
+	return nil
+}
+
+func clearCacheCmd(cliCtx *cli.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return cli.NewExitError("Could not load config: "+err.Error(), -1)
+	}
+
+	if err := clearCache(cfg); err != nil {
+		return err
+	}
+
+	fmt.Println("The credential cache has been cleared.")
+	return nil
+}
+
+func clearCache(cfg *config.Config) error {
+	cache, err := loadCredentialCache(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := cache.Clear(); err != nil {
+		return cli.NewExitError("Could not clear cache: "+err.Error(), -1)
+	}
+
+	return nil
+}
+
+func loadCredentialCache(cfg *config.Config) (*credcache.Cache, error) {
+	var ttl time.Duration
+	if cfg.CredentialCacheTTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(cfg.CredentialCacheTTL)
+		if err != nil {
+			return nil, cli.NewExitError("Invalid credential_cache_ttl: "+err.Error(), -1)
+		}
+	}
+
+	dir, err := credcache.Path()
+	if err != nil {
+		return nil, cli.NewExitError("Could not find cache directory: "+err.Error(), -1)
+	}
+
+	return credcache.New(dir, ttl), nil
+}
+
+// cacheScope identifies the credentials requested by the command. It only
+// relies on names, so it can be resolved without contacting Manifold.
+func cacheScope(cliCtx *cli.Context, cfg *config.Config, projectName string) credcache.Scope {
+	scope := credcache.Scope{
+		Profile:  cfg.Profile,
+		Hostname: cfg.Hostname,
+		Project:  projectName,
+	}
+
+	if !cliCtx.Bool("me") {
+		scope.Team = cliCtx.String("team")
+		if scope.Team == "" {
+			scope.Team = cfg.TeamName
+		}
+	}
+
+	return scope
+}
+
+// unlessOffline skips the given middleware when --offline is set.
+func unlessOffline(f func(*cli.Context) error) func(*cli.Context) error {
+	return func(cliCtx *cli.Context) error {
+		if cliCtx.Bool("offline") {
+			return nil
+		}
+
+		return f(cliCtx)
+	}
+}
+
+// cachedCredentials returns the cached credentials for an --offline command.
+func cachedCredentials(cliCtx *cli.Context, projectName string) (*credcache.Entry, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cli.NewExitError("Could not load config: "+err.Error(), -1)
+	}
+
+	cache, err := loadCredentialCache(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := cache.Get(cacheScope(cliCtx, cfg, projectName))
+	switch err {
+	case nil:
+		return e, nil
+	case credcache.ErrNotFound:
+		return nil, cli.NewExitError("No cached credentials found, run the command online with the cache enabled first", -1)
+	case credcache.ErrExpired:
+		return nil, cli.NewExitError("Cached credentials have expired, run the command online to refresh them", -1)
+	default:
+		return nil, cli.NewExitError("Could not read cached credentials: "+err.Error(), -1)
+	}
+}
+
+// storeCredentials caches the credentials when the cache is enabled. Failing
+// to write the cache never fails the command.
+func storeCredentials(cliCtx *cli.Context, projectName string, resources []credcache.Resource) {
+	cfg, err := config.Load()
+	if err != nil || !cfg.CredentialCache {
+		return
+	}
+
+	cache, err := loadCredentialCache(cfg)
+	if err == nil {
+		err = cache.Put(cacheScope(cliCtx, cfg, projectName), resources)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not cache credentials: %s\n", err)
+	}
+}
+
+// cacheResources groups the credentials by resource, sorted by resource name.
+func cacheResources(resources []*models.Resource,
+	cMap map[manifold.ID][]*models.Credential) []credcache.Resource {
+	var out []credcache.Resource
+
+	for _, r := range resources {
+		credentials, ok := cMap[r.ID]
+		if !ok {
+			continue
+		}
+
+		values := make(map[string]string)
+		for _, c := range credentials {
+			for name, value := range c.Body.Values {
+				values[name] = value
+			}
+		}
+
+		out = append(out, credcache.Resource{
+			Name:   string(r.Body.Name),
+			Values: values,
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+
+	return out
+}
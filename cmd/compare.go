@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/juju/ansiterm"
+	"github.com/manifoldco/go-manifold"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/data/catalog"
+	"github.com/manifoldco/manifold-cli/errs"
+	"github.com/manifoldco/manifold-cli/output"
+	"github.com/manifoldco/manifold-cli/prompts"
+
+	"github.com/manifoldco/manifold-cli/generated/catalog/models"
+)
+
+func compareCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	args := cliCtx.Args()
+	if len(args) < 1 {
+		return errs.NewUsageExitError(cliCtx, cli.NewExitError("A product name is required", -1))
+	}
+
+	productName := args[0]
+	planNames := args[1:]
+
+	providerName, err := validateName(cliCtx, "provider")
+	if err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Analytics, api.Catalog)
+	if err != nil {
+		return err
+	}
+
+	prompts.SpinStart("Fetching Plans")
+	cat, err := catalog.New(ctx, client.Catalog)
+	prompts.SpinStop()
+	if err != nil {
+		return cli.NewExitError("Could not load catalog: "+err.Error(), -1)
+	}
+
+	var provider *models.Provider
+	if providerName != "" {
+		provider, err = client.FetchProvider(providerName)
+		if err != nil {
+			return cli.NewExitError(err, -1)
+		}
+	}
+
+	product, err := catalogProduct(cat, productName, provider)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return cli.NewExitError(fmt.Sprintf("Product %q not found", productName), -1)
+	}
+
+	plans, err := comparedPlans(cat, product, planNames)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"subcommand":    "compare",
+		"product_label": string(product.Body.Label),
+	}
+	client.Analytics.Track(client.Context(), "Viewed Services", &params)
+
+	comparison := comparePlans(product, plans, cat.Regions())
+
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		return writeRecords(format, comparison)
+	}
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Comparing %d plans of %s, differences are highlighted\n\n", len(plans), product.Body.Label)
+
+	w.SetForeground(ansiterm.Gray)
+	fmt.Fprintf(w, "Plan\t%s\n", strings.Join(comparison.Plans, "\t"))
+	w.Reset()
+
+	for _, row := range comparison.Rows {
+		title := color.Faint(row.Title)
+		values := strings.Join(row.Values, "\t")
+		if row.Differs {
+			title = color.Bold(row.Title)
+			values = color.Color(ansiterm.Yellow, values)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\n", title, values)
+	}
+
+	return w.Flush()
+}
+
+// catalogProduct returns the catalog product with the label, optionally
+// offered by provider, or nil when there is none. Labels shared by several
+// providers are an error.
+func catalogProduct(cat *catalog.Catalog, label string, provider *models.Provider) (*models.Product, error) {
+	var product *models.Product
+	for _, p := range cat.Products() {
+		if string(p.Body.Label) != label {
+			continue
+		}
+		if provider != nil && p.Body.ProviderID != provider.ID {
+			continue
+		}
+		if product != nil {
+			return nil, cli.NewExitError(fmt.Sprintf(
+				"Product %q is offered by more than one provider, use --provider", label), -1)
+		}
+		product = p
+	}
+
+	return product, nil
+}
+
+// comparedPlans returns the plans of the product with the given names, or
+// all of its plans when no name is given, from cheapest to most expensive.
+func comparedPlans(cat *catalog.Catalog, product *models.Product, names []string) ([]*models.Plan, error) {
+	var plans []*models.Plan
+	for _, p := range cat.Plans() {
+		if p.Body.ProductID == product.ID {
+			plans = append(plans, p)
+		}
+	}
+
+	if len(names) == 0 {
+		if len(plans) == 0 {
+			return nil, errs.ErrNoPlans
+		}
+		return plans, nil
+	}
+
+	selected := make([]*models.Plan, 0, len(names))
+	for _, name := range names {
+		var plan *models.Plan
+		for _, p := range plans {
+			if string(p.Body.Label) == name {
+				plan = p
+			}
+		}
+		if plan == nil {
+			return nil, cli.NewExitError(fmt.Sprintf("Plan %q not found for product %q",
+				name, product.Body.Label), -1)
+		}
+
+		selected = append(selected, plan)
+	}
+
+	return selected, nil
+}
+
+// comparePlans builds a matrix of the cost, features and regions of the plans,
+// with a column per plan.
+func comparePlans(product *models.Product, plans []*models.Plan,
+	regions []*models.Region) output.PlanComparison {
+	comparison := output.PlanComparison{
+		Product: string(product.Body.Label),
+	}
+
+	cost := output.ComparisonRow{Name: "cost", Title: "Monthly Cost"}
+	for _, p := range plans {
+		comparison.Plans = append(comparison.Plans, string(p.Body.Label))
+		comparison.Costs = append(comparison.Costs, *p.Body.Cost)
+
+		value := "Free"
+		if *p.Body.Cost != 0 {
+			value = "$" + toPrice(*p.Body.Cost)
+		}
+		cost.Values = append(cost.Values, value)
+	}
+	comparison.Rows = append(comparison.Rows, cost)
+
+	// Features are listed in the order the product declares them, followed by
+	// any feature the product doesn't declare
+	var labels []string
+	titles := make(map[string]string)
+	for _, f := range product.Body.FeatureTypes {
+		labels = append(labels, string(f.Label))
+		titles[string(f.Label)] = string(f.Name)
+	}
+
+	var extra []string
+	for _, p := range plans {
+		for _, f := range p.Body.Features {
+			label := string(f.Feature)
+			if _, ok := titles[label]; !ok {
+				titles[label] = label
+				extra = append(extra, label)
+			}
+		}
+	}
+	sort.Strings(extra)
+	labels = append(labels, extra...)
+
+	for _, label := range labels {
+		row := output.ComparisonRow{Name: label, Title: titles[label]}
+		for _, p := range plans {
+			value := "-"
+			for _, f := range p.Body.Features {
+				if string(f.Feature) == label && f.Value != nil {
+					value = *f.Value
+				}
+			}
+			row.Values = append(row.Values, value)
+		}
+		comparison.Rows = append(comparison.Rows, row)
+	}
+
+	names := make(map[manifold.ID]string)
+	for _, r := range regions {
+		names[r.ID] = string(r.Body.Name)
+	}
+
+	available := output.ComparisonRow{Name: "regions", Title: "Regions"}
+	for _, p := range plans {
+		var planRegions []string
+		for _, id := range p.Body.Regions {
+			if name, ok := names[id]; ok {
+				planRegions = append(planRegions, name)
+			}
+		}
+		sort.Strings(planRegions)
+		available.Values = append(available.Values, strings.Join(planRegions, ", "))
+	}
+	comparison.Rows = append(comparison.Rows, available)
+
+	for i, row := range comparison.Rows {
+		for _, v := range row.Values {
+			if v != row.Values[0] {
+				comparison.Rows[i].Differs = true
+			}
+		}
+	}
+
+	return comparison
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/completion"
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/credcache"
+	"github.com/manifoldco/manifold-cli/data/catalog"
+	"github.com/manifoldco/manifold-cli/errs"
+	"github.com/manifoldco/manifold-cli/output"
+)
+
+func init() {
+	completionCmd := cli.Command{
+		Name:      "completion",
+		Usage:     "Print the shell script completing manifold commands",
+		Category:  "UTILITY",
+		ArgsUsage: "<" + strings.Join(completion.Shells(), "|") + ">",
+		Description: "Load the script in your shell to complete commands along with the labels of\n" +
+			"   your resources, projects, teams, products and plans. For example:\n\n" +
+			"   bash: eval \"$(manifold completion bash)\"\n" +
+			"   zsh:  eval \"$(manifold completion zsh)\"\n" +
+			"   fish: manifold completion fish | source",
+		Action: printCompletion,
+	}
+
+	cmds = append(cmds, completionCmd)
+}
+
+func printCompletion(cliCtx *cli.Context) error {
+	if err := maxOptionalArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+
+	script, err := completion.Script(cliCtx.Args().First())
+	if err != nil {
+		return errs.NewUsageExitError(cliCtx, cli.NewExitError(err.Error(), -1))
+	}
+
+	fmt.Print(script)
+	return nil
+}
+
+// completionKinds maps flags and argument names to the values suggested for
+// them.
+var completionKinds = map[string]string{
+	"project":  "projects",
+	"resource": "resources",
+	"team":     "teams",
+	"product":  "products",
+	"plan":     "plans",
+	"provider": "providers",
+	"region":   "regions",
+	"format":   "formats",
+	"output":   "outputs",
+	"profile":  "profiles",
+}
+
+// withCompletion sets the completion of every command, recursively.
+func withCompletion(commands []cli.Command) []cli.Command {
+	for i := range commands {
+		commands[i].BashComplete = completeArgs
+		commands[i].Subcommands = withCompletion(commands[i].Subcommands)
+	}
+
+	return commands
+}
+
+// completeArgs suggests flag names when a dash is typed, values for the flag
+// being completed, or values for the next positional argument. Failures are
+// silent, as there is nowhere to report them.
+func completeArgs(cliCtx *cli.Context) {
+	cmd := cliCtx.Command
+	flags := cmd.Flags
+	if cmd.Name == "" {
+		flags = cliCtx.App.Flags
+	}
+
+	if strings.HasPrefix(os.Getenv(completion.EnvWord), "-") {
+		for _, f := range flags {
+			for _, name := range flagNames(f) {
+				prefix := "--"
+				if len(name) == 1 {
+					prefix = "-"
+				}
+				fmt.Fprintln(cliCtx.App.Writer, prefix+name)
+			}
+		}
+		return
+	}
+
+	// The last word is always --generate-bash-completion
+	words := os.Args[1 : len(os.Args)-1]
+	if len(words) > 0 && strings.HasPrefix(words[len(words)-1], "-") {
+		name := strings.TrimLeft(words[len(words)-1], "-")
+		if f := findFlag(flags, name); f != nil && !isBoolFlag(f) {
+			printCompletions(cliCtx, completionKinds[flagNames(f)[0]], words)
+			return
+		}
+	}
+
+	if cmd.Name == "" {
+		cli.DefaultAppComplete(cliCtx)
+		return
+	}
+
+	usage := strings.Fields(cmd.ArgsUsage)
+	pos := cliCtx.NArg()
+	if len(usage) == 0 {
+		return
+	}
+	if pos >= len(usage) {
+		if !strings.HasSuffix(usage[len(usage)-1], "...]") {
+			return
+		}
+		pos = len(usage) - 1
+	}
+
+	arg := strings.Trim(usage[pos], "[]<>.")
+	printCompletions(cliCtx, completionKinds[strings.TrimSuffix(arg, "-name")], words)
+}
+
+func printCompletions(cliCtx *cli.Context, kind string, words []string) {
+	if kind == "" {
+		return
+	}
+
+	values, err := completionValues(kind, words)
+	if err != nil {
+		return
+	}
+
+	for _, v := range values {
+		fmt.Fprintln(cliCtx.App.Writer, v)
+	}
+}
+
+// completionValues returns the values of a kind. Values fetched from the API
+// are cached for a short while, per host and team.
+func completionValues(kind string, words []string) ([]string, error) {
+	switch kind {
+	case "formats":
+		return formats, nil
+	case "outputs":
+		var values []string
+		for _, f := range output.Formats {
+			values = append(values, string(f))
+		}
+		return values, nil
+	case "profiles":
+		profiles, _, err := config.Profiles()
+		return profiles, err
+	case "products", "plans", "regions":
+		return catalogCompletions(kind, words)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := credcache.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	cache := completion.NewCache(filepath.Join(dir, "completion.json"), completion.DefaultTTL)
+	key := strings.Join([]string{cfg.Hostname, cfg.TeamID, kind}, "/")
+	if values, ok := cache.Get(key); ok {
+		return values, nil
+	}
+
+	values, err := fetchCompletions(kind, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(values)
+	cache.Put(key, values)
+	return values, nil
+}
+
+func fetchCompletions(kind string, cfg *config.Config) ([]string, error) {
+	ctx := context.Background()
+
+	client, err := api.New(api.Catalog, api.Identity, api.Marketplace)
+	if err != nil {
+		return nil, err
+	}
+
+	var teamID *manifold.ID
+	if cfg.TeamID != "" {
+		id, err := manifold.DecodeIDFromString(cfg.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		teamID = &id
+	}
+
+	var values []string
+	switch kind {
+	case "projects":
+		projects, err := clients.FetchProjects(ctx, client.Marketplace, teamID)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			values = append(values, string(p.Body.Label))
+		}
+	case "resources":
+		resources, err := clients.FetchResources(ctx, client.Marketplace, teamID, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resources {
+			values = append(values, string(r.Body.Label))
+		}
+	case "teams":
+		teams, err := clients.FetchTeams(ctx, client.Identity)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range teams {
+			values = append(values, string(t.Body.Label))
+		}
+	case "providers":
+		providers, err := client.FetchProviders()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range providers {
+			values = append(values, string(p.Body.Label))
+		}
+	}
+
+	return values, nil
+}
+
+// catalogCompletions reads products, plans and regions from the catalog,
+// which is already cached on disk. Plans are limited to the product given
+// through --product or as the first argument, when there is one.
+func catalogCompletions(kind string, words []string) ([]string, error) {
+	client, err := api.New(api.Catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	cat, err := catalog.New(context.Background(), client.Catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var values []string
+	add := func(v string) {
+		if !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+
+	switch kind {
+	case "products":
+		for _, p := range cat.Products() {
+			add(string(p.Body.Label))
+		}
+	case "regions":
+		for _, r := range cat.Regions() {
+			add(regionKey(r))
+		}
+	case "plans":
+		products := make(map[manifold.ID]bool)
+		for _, p := range cat.Products() {
+			for _, w := range words {
+				if string(p.Body.Label) == w {
+					products[p.ID] = true
+				}
+			}
+		}
+
+		for _, p := range cat.Plans() {
+			if len(products) == 0 || products[p.Body.ProductID] {
+				add(string(p.Body.Label))
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func flagNames(f cli.Flag) []string {
+	var names []string
+	for _, n := range strings.Split(f.GetName(), ",") {
+		names = append(names, strings.TrimSpace(n))
+	}
+
+	return names
+}
+
+func findFlag(flags []cli.Flag, name string) cli.Flag {
+	for _, f := range flags {
+		for _, n := range flagNames(f) {
+			if n == name {
+				return f
+			}
+		}
+	}
+
+	return nil
+}
+
+func isBoolFlag(f cli.Flag) bool {
+	switch f.(type) {
+	case cli.BoolFlag, cli.BoolTFlag:
+		return true
+	}
+
+	return false
+}
@@ -10,9 +10,11 @@ import (
 
 	"github.com/manifoldco/manifold-cli/api"
 	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/config"
 	"github.com/manifoldco/manifold-cli/errs"
 	"github.com/manifoldco/manifold-cli/middleware"
 	"github.com/manifoldco/manifold-cli/prompts"
+	"github.com/manifoldco/manifold-cli/secrets"
 
 	"github.com/manifoldco/manifold-cli/generated/marketplace/client/credential"
 	"github.com/manifoldco/manifold-cli/generated/marketplace/models"
@@ -46,6 +48,18 @@ func init() {
 				Action: middleware.Chain(middleware.EnsureSession, middleware.LoadTeamPrefs,
 					configUnsetCmd),
 			},
+			{
+				Name:  "migrate-token",
+				Usage: "Move your session token out of ~/.manifoldrc into a secret store",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "store",
+						Usage: "Use this store: keyring, file or auto to prefer the keyring",
+						Value: secrets.Auto,
+					},
+				},
+				Action: migrateTokenCmd,
+			},
 		},
 	}
 
@@ -148,3 +162,21 @@ func configUnsetCmd(cliCtx *cli.Context) error {
 	}
 	return patchConfig(cliCtx, req)
 }
+
+func migrateTokenCmd(cliCtx *cli.Context) error {
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return cli.NewExitError("Could not load config: "+err.Error(), -1)
+	}
+
+	if err := cfg.MoveToken(cliCtx.String("store")); err != nil {
+		return cli.NewExitError("Could not migrate token: "+err.Error(), -1)
+	}
+
+	fmt.Printf("Your session token is stored in the %s store.\n", cfg.TokenStore)
+	return nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/ansiterm"
+	"github.com/manifoldco/go-manifold"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/data/catalog"
+	"github.com/manifoldco/manifold-cli/errs"
+	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/output"
+	"github.com/manifoldco/manifold-cli/prompts"
+
+	"github.com/manifoldco/manifold-cli/generated/marketplace/models"
+)
+
+func init() {
+	costsCmd := cli.Command{
+		Name:     "costs",
+		Usage:    "Report the monthly cost of your resources by team, project and product",
+		Category: "ADMINISTRATIVE",
+		Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
+			middleware.LoadTeamPrefs, costsCmd),
+		Flags: append(teamFlags, []cli.Flag{
+			projectFlag(),
+			cli.BoolFlag{
+				Name:  "all-teams",
+				Usage: "Report the resources of every team you belong to, along with your own",
+			},
+			cli.BoolFlag{
+				Name:  "csv",
+				Usage: "Write a CSV line per resource instead of a summary",
+			},
+			outputFlag(),
+		}...),
+	}
+
+	cmds = append(cmds, costsCmd)
+}
+
+func costsCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	projectName, err := validateName(cliCtx, "project")
+	if err != nil {
+		return err
+	}
+
+	teamID, err := validateTeamID(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Catalog, api.Identity, api.Marketplace)
+	if err != nil {
+		return err
+	}
+
+	prompts.SpinStart("Fetching Resources")
+	costs, err := fetchCosts(ctx, cliCtx, client, teamID, projectName)
+	prompts.SpinStop()
+	if err != nil {
+		return err
+	}
+
+	if len(costs) == 0 {
+		return errs.ErrNoResources
+	}
+
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		return writeRecords(format, costs)
+	}
+
+	if cliCtx.Bool("csv") {
+		return writeCostsCSV(costs)
+	}
+
+	return writeCostsTable(costs)
+}
+
+// fetchCosts returns the cost of every catalog resource of the owners,
+// sorted by owner, project, product and name. Custom resources are free, so
+// they aren't reported.
+func fetchCosts(ctx context.Context, cliCtx *cli.Context, client *api.API, teamID *manifold.ID,
+	projectName string) ([]output.Cost, error) {
+	cat, err := catalog.New(ctx, client.Catalog)
+	if err != nil {
+		return nil, cli.NewExitError("Failed to fetch catalog data: "+err.Error(), -1)
+	}
+
+	owners := []*manifold.ID{teamID}
+	if cliCtx.Bool("all-teams") {
+		teams, err := clients.FetchTeams(ctx, client.Identity)
+		if err != nil {
+			return nil, cli.NewExitError("Failed to fetch the list of teams: "+err.Error(), -1)
+		}
+
+		owners = []*manifold.ID{nil}
+		for _, t := range teams {
+			id := t.ID
+			owners = append(owners, &id)
+		}
+	}
+
+	// Plans missing from the catalog are remembered by FetchPlanById, so they
+	// must be tracked here to be flagged for every resource using them
+	unlisted := make(map[manifold.ID]bool)
+
+	var costs []output.Cost
+	for _, owner := range owners {
+		resources, err := clients.FetchResources(ctx, client.Marketplace, owner, "")
+		if err != nil {
+			return nil, cli.NewExitError("Failed to fetch the list of provisioned "+
+				"resources: "+err.Error(), -1)
+		}
+
+		list, err := groupResources(ctx, client, resources, owner)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, group := range list.groups {
+			if projectName != "" && group.project != projectName {
+				continue
+			}
+
+			for _, r := range group.resources {
+				if *r.Body.Source == "custom" {
+					continue
+				}
+
+				cost, err := resourceCost(ctx, cat, r, unlisted)
+				if err != nil {
+					return nil, err
+				}
+
+				cost.Owner = group.owner
+				cost.Project = group.project
+				costs = append(costs, cost)
+			}
+		}
+	}
+
+	sort.SliceStable(costs, func(i, j int) bool {
+		a, b := costs[i], costs[j]
+		switch {
+		case a.Owner != b.Owner:
+			return a.Owner < b.Owner
+		case a.Project != b.Project:
+			return a.Project < b.Project
+		case a.Product != b.Product:
+			return a.Product < b.Product
+		default:
+			return a.Resource < b.Resource
+		}
+	})
+
+	return costs, nil
+}
+
+// resourceCost returns the monthly cost of a resource, flagging resources on
+// plans which aren't listed in the catalog.
+func resourceCost(ctx context.Context, cat *catalog.Catalog, r *models.Resource,
+	unlisted map[manifold.ID]bool) (output.Cost, error) {
+	if _, err := cat.GetPlan(*r.Body.PlanID); err != nil {
+		unlisted[*r.Body.PlanID] = true
+	}
+
+	product, plan, err := resourcePlan(ctx, cat, r)
+	if err != nil {
+		return output.Cost{}, err
+	}
+
+	return output.Cost{
+		Resource: string(r.Body.Label),
+		Product:  string(product.Body.Label),
+		Plan:     string(plan.Body.Label),
+		Cost:     *plan.Body.Cost,
+		Unlisted: unlisted[plan.ID],
+	}, nil
+}
+
+func writeCostsCSV(costs []output.Cost) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"owner", "project", "resource", "product", "plan", "monthly_cost", "unlisted"})
+
+	for _, c := range costs {
+		w.Write([]string{c.Owner, c.Project, c.Resource, c.Product, c.Plan, toPrice(c.Cost),
+			strconv.FormatBool(c.Unlisted)})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return cli.NewExitError("Could not write CSV: "+err.Error(), -1)
+	}
+
+	return nil
+}
+
+// writeCostsTable sums the costs by product within every project, with a
+// total for every project and owner.
+func writeCostsTable(costs []output.Cost) error {
+	type row struct {
+		owner, project, product string
+		resources               int
+		cost                    int64
+		unlisted                bool
+	}
+
+	var rows []*row
+	var total int64
+	var unlisted []string
+	for _, c := range costs {
+		total += c.Cost
+		if c.Unlisted {
+			unlisted = append(unlisted, c.Resource)
+		}
+
+		last := len(rows) - 1
+		if last < 0 || rows[last].owner != c.Owner || rows[last].project != c.Project ||
+			rows[last].product != c.Product {
+			rows = append(rows, &row{owner: c.Owner, project: c.Project, product: c.Product})
+			last++
+		}
+
+		rows[last].resources++
+		rows[last].cost += c.Cost
+		rows[last].unlisted = rows[last].unlisted || c.Unlisted
+	}
+
+	fmt.Printf("%d resources cost %s per month\n\n", len(costs), displayCost(total))
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	w.SetForeground(ansiterm.Gray)
+	fmt.Fprintln(w, "Owner\tProject\tProduct\tResources\tMonthly Cost")
+	w.Reset()
+
+	var projectCost, ownerCost int64
+	for i, r := range rows {
+		product := r.product
+		if r.unlisted {
+			product += "*"
+		}
+
+		project := r.project
+		if project == "" {
+			project = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", r.owner, project, product, r.resources,
+			displayCost(r.cost))
+
+		projectCost += r.cost
+		ownerCost += r.cost
+
+		next := i + 1
+		lastOfOwner := next == len(rows) || rows[next].owner != r.owner
+		if lastOfOwner || rows[next].project != r.project {
+			fmt.Fprintf(w, "%s\t%s\t%s\t\t%s\n", r.owner, project, color.Faint("project total"),
+				color.Faint(displayCost(projectCost)))
+			projectCost = 0
+		}
+		if lastOfOwner {
+			fmt.Fprintf(w, "%s\t\t%s\t\t%s\n", r.owner, color.Bold("total"),
+				color.Bold(displayCost(ownerCost)))
+			ownerCost = 0
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(unlisted) > 0 {
+		fmt.Printf("\n* Resources on plans which are no longer listed: %s\n",
+			strings.Join(unlisted, ", "))
+	}
+
+	return nil
+}
+
+func displayCost(cost int64) string {
+	if cost == 0 {
+		return "Free"
+	}
+
+	return "$" + toPrice(cost)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-openapi/strfmt"
@@ -85,10 +86,8 @@ func create(cliCtx *cli.Context) error {
 		return err
 	}
 
-	regionName, err := validateName(cliCtx, "region")
-	if err != nil {
-		return err
-	}
+	// Regions are matched by name or platform::location, neither are labels
+	regionName := cliCtx.String("region")
 
 	custom := cliCtx.Bool("custom")
 	if custom && (planName != "" || productName != "" || regionName != "") {
@@ -151,6 +150,13 @@ func create(cliCtx *cli.Context) error {
 		}
 
 		regions := filterRegionsForPlan(catalog.Regions(), plan.Body.Regions)
+		if regionName != "" {
+			regions = filterRegionsByName(regions, regionName)
+			if len(regions) == 0 {
+				return errs.ErrRegionNotFound
+			}
+		}
+
 		regionIdx, _, err := prompts.SelectRegion(regions)
 		if err != nil {
 			return prompts.HandleSelectError(err, "Could not select region.")
@@ -391,6 +397,8 @@ func waitForOp(ctx context.Context, pClient *provisioning.Provisioning, op *pMod
 		return res.Payload, nil
 	}
 
+	report, _ := ctx.Value(opReporterKey{}).(func(string))
+
 	ticker := time.NewTicker(time.Second * 5)
 	defer ticker.Stop()
 	for {
@@ -400,6 +408,10 @@ func waitForOp(ctx context.Context, pClient *provisioning.Provisioning, op *pMod
 			return nil, err
 		}
 
+		if report != nil {
+			report(opState(op))
+		}
+
 		switch provision := op.Body.(type) {
 		case *pModels.Provision:
 			switch *provision.State {
@@ -449,6 +461,35 @@ func waitForOp(ctx context.Context, pClient *provisioning.Provisioning, op *pMod
 	}
 }
 
+type opReporterKey struct{}
+
+// withOpReporter returns a context making waitForOp call report with the
+// state of the operation every time it's polled.
+func withOpReporter(ctx context.Context, report func(state string)) context.Context {
+	return context.WithValue(ctx, opReporterKey{}, report)
+}
+
+func opState(op *pModels.Operation) string {
+	var state *string
+	switch body := op.Body.(type) {
+	case *pModels.Provision:
+		state = body.State
+	case *pModels.Resize:
+		state = body.State
+	case *pModels.Deprovision:
+		state = body.State
+	case *pModels.Move:
+		state = body.State
+	case *pModels.ProjectDelete:
+		state = body.State
+	}
+
+	if state == nil {
+		return ""
+	}
+	return *state
+}
+
 func filterPlansByProductID(plans []*cModels.Plan, productID manifold.ID) []*cModels.Plan {
 	out := make([]*cModels.Plan, 0, len(plans))
 	for _, p := range plans {
@@ -477,6 +518,31 @@ func filterRegionsForPlan(regions []*cModels.Region, regionIDs []manifold.ID) []
 	return out
 }
 
+// filterRegionsByName returns the regions matching the name, or the
+// platform::location key.
+func filterRegionsByName(regions []*cModels.Region, name string) []*cModels.Region {
+	var out []*cModels.Region
+	for _, r := range regions {
+		if strings.EqualFold(string(r.Body.Name), name) || regionKey(r) == name {
+			out = append(out, r)
+		}
+	}
+
+	return out
+}
+
+func regionKey(r *cModels.Region) string {
+	var platform, location string
+	if r.Body.Platform != nil {
+		platform = *r.Body.Platform
+	}
+	if r.Body.Location != nil {
+		location = *r.Body.Location
+	}
+
+	return platform + "::" + location
+}
+
 func toPrice(cost int64) string {
 	s := strconv.Itoa(int(cost))
 	if len(s) == 0 {
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/juju/ansiterm"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/output"
+	"github.com/manifoldco/manifold-cli/prompts"
+	"github.com/manifoldco/manifold-cli/subscription"
+)
+
+func creditsCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	teamID, err := validateTeamID(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Billing)
+	if err != nil {
+		return err
+	}
+
+	prompts.SpinStart("Fetching Credits")
+	events, err := clients.FetchSubscriptionEvents(ctx, client.Billing, teamID)
+	prompts.SpinStop()
+	if err != nil {
+		return cli.NewExitError("Could not retrieve subscription events: "+err.Error(), -1)
+	}
+
+	apps, orphans := subscription.Ledger(events, time.Now())
+	for _, e := range orphans {
+		fmt.Fprintf(os.Stderr, "Warning: rollover %s references an unknown credit and was not counted\n",
+			e.ID)
+	}
+
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		return writeRecords(format, creditRecords(apps))
+	}
+
+	if len(apps) == 0 {
+		fmt.Println("No coupons or credits applied. Use `manifold billing redeem` to apply a coupon.")
+		return nil
+	}
+
+	fmt.Printf("%s of credit remaining\n\n", color.Bold("$"+toPrice(subscription.Remaining(apps))))
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	w.SetForeground(ansiterm.Gray)
+	fmt.Fprintln(w, "Credit\tPeriod\tOpening\tUsed\tRemaining\tExpires")
+	w.Reset()
+
+	for _, app := range apps {
+		name := app.Code
+		if name == "" {
+			name = app.Reason
+		}
+
+		for i, p := range app.Periods {
+			title := color.Faint(name)
+			if i == 0 {
+				title = name
+			}
+
+			used, remaining, expires := "-", "-", "-"
+			if p.Closed {
+				used = "$" + toPrice(p.Used)
+			}
+			if i == len(app.Periods)-1 {
+				remaining = "$" + toPrice(app.Balance)
+				expires = p.ExpiresAt.Format("2006-01-02")
+				if app.ExhaustedIn != "" {
+					remaining, expires = color.Faint("used up"), "-"
+				}
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t$%s\t%s\t%s\t%s\n", title, p.Period, toPrice(p.Opening), used,
+				remaining, expires)
+		}
+	}
+
+	return w.Flush()
+}
+
+func creditRecords(apps []*subscription.Application) []output.Credit {
+	records := make([]output.Credit, 0, len(apps))
+	for _, app := range apps {
+		r := output.Credit{
+			ID:          app.ID.String(),
+			Code:        app.Code,
+			Reason:      app.Reason,
+			AppliedAt:   app.AppliedAt.UTC().Format(time.RFC3339),
+			Amount:      app.Amount,
+			Balance:     app.Balance,
+			ExhaustedIn: app.ExhaustedIn,
+		}
+		if app.CouponID != nil {
+			r.CouponID = app.CouponID.String()
+		}
+
+		for _, p := range app.Periods {
+			period := output.CreditPeriod{
+				Period:    p.Period,
+				Opening:   p.Opening,
+				ExpiresAt: p.ExpiresAt.Format(time.RFC3339),
+			}
+			if p.Closed {
+				used := p.Used
+				period.Used = &used
+			}
+			r.Periods = append(r.Periods, period)
+		}
+
+		records = append(records, r)
+	}
+
+	return records
+}
+
+func verifyEventsCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return cli.NewExitError("Could not load config: "+err.Error(), -1)
+	}
+
+	key, err := subscription.ParsePublicKey(cfg.BillingKey())
+	if err == subscription.ErrNoPublicKey {
+		return cli.NewExitError("No Manifold public key is pinned in this build, build it with "+
+			"MANIFOLD_BILLING_PUBLIC_KEY or, for other hosts, set billing_public_key in your profile", -1)
+	}
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	teamID, err := validateTeamID(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Billing)
+	if err != nil {
+		return err
+	}
+
+	prompts.SpinStart("Fetching Subscription Events")
+	events, err := clients.FetchSubscriptionEvents(ctx, client.Billing, teamID)
+	prompts.SpinStop()
+	if err != nil {
+		return cli.NewExitError("Could not retrieve subscription events: "+err.Error(), -1)
+	}
+
+	result := output.Verification{Events: len(events), Verified: true}
+	err = subscription.Verify(events, key)
+	if broken, ok := err.(*subscription.BrokenLink); ok {
+		result.Verified = false
+		result.BrokenEvent = broken.Event.ID.String()
+		result.Reason = broken.Reason
+	} else if err != nil {
+		return cli.NewExitError("Could not verify subscription events: "+err.Error(), -1)
+	}
+
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		if err := writeRecords(format, result); err != nil {
+			return err
+		}
+		if !result.Verified {
+			return cli.NewExitError("", -1)
+		}
+		return nil
+	}
+
+	if !result.Verified {
+		return cli.NewExitError("The subscription event log was tampered with: "+err.Error(), -1)
+	}
+
+	fmt.Printf("Verified %d subscription events, the log is intact.\n", len(events))
+	return nil
+}
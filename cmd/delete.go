@@ -34,6 +34,7 @@ func init() {
 		Flags: append(teamFlags, []cli.Flag{
 			projectFlag(),
 			skipFlag(),
+			yesFlag(),
 		}...),
 	}
 
@@ -128,9 +129,14 @@ func deleteCmd(cliCtx *cli.Context) error {
 			project.Body.Label, resource.Body.Label)
 	}
 
-	_, err = prompts.Confirm(msg)
-	if err != nil {
-		return cli.NewExitError("Resource not deleted", -1)
+	if !cliCtx.Bool("yes") {
+		_, err = prompts.Confirm(msg, "--yes")
+		if _, ok := err.(*errs.NonInteractiveError); ok {
+			return err
+		}
+		if err != nil {
+			return cli.NewExitError("Resource not deleted", -1)
+		}
 	}
 
 	spin := prompts.NewSpinner(fmt.Sprintf("Deleting resource \"%s\"", resource.Body.Label))
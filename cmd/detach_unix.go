@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts the process in its own process group, so it isn't
+// interrupted along with the command starting it.
+func detach(proc *exec.Cmd) {
+	proc.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts the process in its own process group, so it isn't
+// interrupted along with the command starting it.
+func detach(proc *exec.Cmd) {
+	proc.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/devserver"
+)
+
+func init() {
+	devServerCmd := cli.Command{
+		Name:     "dev-server",
+		Usage:    "Serve an in-memory Manifold API for local development",
+		Category: "UTILITY",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "addr",
+				Usage: "Listen on this address",
+				Value: "127.0.0.1:8080",
+			},
+			cli.StringFlag{
+				Name:  "specs",
+				Usage: "Serve the OpenAPI specs found in this directory instead of the bundled ones",
+			},
+			cli.StringFlag{
+				Name:  "seed",
+				Usage: "Seed the API with the collections of this YAML or JSON file",
+			},
+		},
+		Action: devServerCmd,
+	}
+
+	cmds = append(cmds, devServerCmd)
+}
+
+func devServerCmd(cliCtx *cli.Context) error {
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	dir := cliCtx.String("specs")
+	if dir == "" {
+		var err error
+		dir, err = devserver.SpecsDir()
+		if err != nil {
+			return cli.NewExitError(err.Error(), -1)
+		}
+	}
+
+	s, err := devserver.New(dir)
+	if err != nil {
+		return cli.NewExitError("Could not load specs: "+err.Error(), -1)
+	}
+
+	if seed := cliCtx.String("seed"); seed != "" {
+		if err := s.SeedFile(seed); err != nil {
+			return cli.NewExitError("Could not seed the API: "+err.Error(), -1)
+		}
+	}
+
+	l, err := net.Listen("tcp", cliCtx.String("addr"))
+	if err != nil {
+		return cli.NewExitError("Could not listen: "+err.Error(), -1)
+	}
+
+	// Profiles only route requests by path to loopback addresses
+	addr := l.Addr().String()
+	if tcp, ok := l.Addr().(*net.TCPAddr); ok && tcp.IP.IsUnspecified() {
+		addr = net.JoinHostPort("127.0.0.1", strconv.Itoa(tcp.Port))
+	}
+	fmt.Printf("Serving the Manifold API at http://%s\n\n", addr)
+	fmt.Println("Point a profile at it with:")
+	fmt.Printf("  manifold profile add dev --hostname %s --scheme http\n", addr)
+	fmt.Println("  manifold --profile dev login")
+
+	if err := http.Serve(l, s); err != nil {
+		return cli.NewExitError("Could not serve: "+err.Error(), -1)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/ansiterm"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/dotenv"
+	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/prompts"
+)
+
+func init() {
+	envCmd := cli.Command{
+		Name:     "env",
+		Usage:    "Manage local environment files",
+		Category: "CONFIGURATION",
+		Subcommands: []cli.Command{
+			{
+				Name:  "sync",
+				Usage: "Write the project credentials into a .env file",
+				Flags: append(teamFlags, []cli.Flag{
+					projectFlag(),
+					envFileFlag(),
+					yesFlag(),
+				}...),
+				Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
+					middleware.LoadTeamPrefs, envSyncCmd),
+			},
+		},
+	}
+
+	cmds = append(cmds, envCmd)
+}
+
+func envFileFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:   "file",
+		Usage:  "Path of the .env file, defaults to the one next to .manifold.yml",
+		EnvVar: "MANIFOLD_ENV_FILE",
+	}
+}
+
+func envSyncCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	projectName, err := requiredName(cliCtx, "project")
+	if err != nil {
+		return err
+	}
+
+	teamID, err := validateTeamID(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	path, err := envFilePath(cliCtx)
+	if err != nil {
+		return cli.NewExitError("Could not find .env file: "+err.Error(), -1)
+	}
+
+	client, err := api.New(api.Analytics, api.Marketplace)
+	if err != nil {
+		return err
+	}
+
+	prompts.SpinStart("Fetching Credentials")
+	p, err := clients.FetchProjectByLabel(ctx, client.Marketplace, teamID, projectName)
+	if err != nil {
+		prompts.SpinStop()
+		return cli.NewExitError(fmt.Sprintf("Could not retrieve project: %s", err), -1)
+	}
+
+	cMap, err := fetchProjectCredentials(ctx, client.Marketplace, p, true)
+	prompts.SpinStop()
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Could not retrieve credentials: %s", err), -1)
+	}
+
+	credentials, err := flattenCMap(cMap)
+	if err != nil {
+		return cli.NewExitError("Could not flatten credential map: "+err.Error(), -1)
+	}
+
+	f, err := dotenv.Load(path)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Could not read %s: %s", path, err), -1)
+	}
+
+	diff := f.Sync(credentials)
+	writeEnvDiff(diff)
+
+	if diff.Empty() {
+		fmt.Printf("%s is already up to date\n", path)
+		return nil
+	}
+
+	if !cliCtx.Bool("yes") {
+		_, err = prompts.Confirm(fmt.Sprintf("Write these changes to %s", path), "--yes")
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := f.Write(path); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Could not write %s: %s", path, err), -1)
+	}
+
+	params := map[string]string{
+		"format":  "dotenv",
+		"project": projectName,
+	}
+	client.Analytics.Track(ctx, "Fetch Credentials", &params)
+
+	fmt.Printf("%s has been updated\n", path)
+	return nil
+}
+
+// envFilePath returns the file given through --file, or the .env file next to
+// the closest .manifold.yml.
+func envFilePath(cliCtx *cli.Context) (string, error) {
+	if path := cliCtx.String("file"); path != "" {
+		return path, nil
+	}
+
+	yml, err := config.LoadYaml(true)
+	if err != nil {
+		return "", err
+	}
+
+	if yml.Path != "" {
+		return filepath.Join(filepath.Dir(yml.Path), ".env"), nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(wd, ".env"), nil
+}
+
+// writeEnvDiff prints the keys added, changed and removed by a sync. Values
+// are never printed.
+func writeEnvDiff(diff dotenv.Diff) {
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 1, ' ', 0)
+
+	for _, k := range diff.Added {
+		fmt.Fprintf(w, "%s %s\n", color.Color(ansiterm.Green, "+"), k)
+	}
+	for _, k := range diff.Changed {
+		fmt.Fprintf(w, "%s %s\n", color.Color(ansiterm.Yellow, "~"), k)
+	}
+	for _, k := range diff.Removed {
+		fmt.Fprintf(w, "%s %s\n", color.Color(ansiterm.Red, "-"), k)
+	}
+	for _, k := range diff.Skipped {
+		fmt.Fprintf(w, "%s %s %s\n", color.Faint("="), k,
+			color.Faint("(defined outside the managed block, skipped)"))
+	}
+
+	w.Flush()
+}
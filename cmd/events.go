@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/juju/ansiterm"
@@ -12,9 +14,12 @@ import (
 
 	"github.com/manifoldco/go-manifold"
 	"github.com/manifoldco/go-manifold/events"
+	"github.com/manifoldco/manifold-cli/activity"
 	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/clients"
 	"github.com/manifoldco/manifold-cli/color"
 	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/output"
 	"github.com/manifoldco/manifold-cli/prompts"
 )
 
@@ -29,7 +34,25 @@ func init() {
 				Usage: "List all events",
 				Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
 					middleware.LoadTeamPrefs, eventsList),
-				Flags: append(teamFlags, limitFlag(), offsetFlag(), verboseFlag()),
+				Flags: append(append(teamFlags, eventFilterFlags()...), limitFlag(), offsetFlag(),
+					verboseFlag(), outputFlag(),
+					cli.BoolFlag{
+						Name:  "follow, f",
+						Usage: "Keep polling for new events and print them as they appear",
+					},
+					cli.DurationFlag{
+						Name:  "interval",
+						Usage: "How often events are polled in follow mode",
+						Value: 10 * time.Second,
+					},
+				),
+			},
+			{
+				Name:  "export",
+				Usage: "Export events as an audit log to a file, stdout or syslog",
+				Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
+					middleware.LoadTeamPrefs, eventsExport),
+				Flags: append(append(teamFlags, eventFilterFlags()...), eventsExportFlags()...),
 			},
 		},
 	}
@@ -37,38 +60,118 @@ func init() {
 	cmds = append(cmds, eventsCmd)
 }
 
-func eventsList(cliCtx *cli.Context) error {
-	var scopeID manifold.ID
+// eventFilterFlags returns the flags filtering events. The project is set
+// through --for-project, which unlike --project isn't filled in from the
+// directory's .manifold.yml.
+func eventFilterFlags() []cli.Flag {
+	return []cli.Flag{
+		resourceFlag(),
+		cli.StringFlag{
+			Name:  "for-project",
+			Usage: "Only include events of resources in this project",
+		},
+		cli.StringSliceFlag{
+			Name:  "type",
+			Usage: "Only include events of this type: provisioned, deprovisioned, resized, failed or measured",
+		},
+		cli.StringSliceFlag{
+			Name:  "actor",
+			Usage: "Only include events performed by this user, by name, email or ID",
+		},
+		cli.StringSliceFlag{
+			Name:  "source",
+			Usage: "Only include events coming from this source: cli, dashboard or system",
+		},
+		cli.StringFlag{
+			Name:  "since",
+			Usage: "Only include events after a date, a RFC 3339 time or a duration ago such as 12h or 7d",
+		},
+		cli.StringFlag{
+			Name:  "until",
+			Usage: "Only include events before a date, a RFC 3339 time or a duration ago such as 12h or 7d",
+		},
+	}
+}
 
-	me := cliCtx.Bool("me")
-	if me {
-		userID, err := loadUserID(context.Background())
-		if err != nil {
-			return err
+// eventFiltered returns whether any of the event filter flags is set.
+func eventFiltered(cliCtx *cli.Context) bool {
+	for _, name := range []string{"resource", "for-project", "since", "until"} {
+		if cliCtx.String(name) != "" {
+			return true
 		}
-		scopeID = *userID
-	} else {
-		teamID, err := validateTeamID(cliCtx)
-		if err != nil {
-			return err
+	}
+	for _, name := range []string{"type", "actor", "source"} {
+		if len(cliCtx.StringSlice(name)) > 0 {
+			return true
 		}
-		scopeID = *teamID
 	}
 
-	client, err := api.New(api.Activity, api.Identity)
+	return false
+}
+
+func eventsList(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	follow := cliCtx.Bool("follow")
+	if follow && cliCtx.String("until") != "" {
+		return cli.NewExitError("--until can't be used with --follow", -1)
+	}
+	if follow && cliCtx.Duration("interval") <= 0 {
+		return cli.NewExitError("--interval must be greater than zero", -1)
+	}
+
+	scopeID, teamID, err := eventScope(ctx, cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Activity, api.Identity, api.Marketplace)
+	if err != nil {
+		return err
+	}
+
+	filter, err := eventFilter(ctx, cliCtx, client, teamID)
 	if err != nil {
 		return err
 	}
 
 	prompts.SpinStart("Fetching Activities")
-	events, err := client.Events(scopeID)
+	evts, err := client.Events(scopeID)
 
 	prompts.SpinStop()
 	if err != nil {
 		return cli.NewExitError("Could not retrieve events: "+err.Error(), -1)
 	}
 
-	err = writeEventsList(cliCtx, events)
+	evts = filter.Apply(evts)
+	format := outputFormat(cliCtx)
+
+	if follow {
+		return followEvents(cliCtx, format, evts, func() ([]*events.Event, error) {
+			next, err := client.Events(scopeID)
+			if err != nil {
+				return nil, err
+			}
+			return filter.Apply(next), nil
+		})
+	}
+
+	min, max := limitCollection(len(evts), cliCtx.Int("limit"), cliCtx.Int("offset"))
+
+	if format.IsMachine() {
+		records := make([]output.Event, 0, max-min)
+		for _, e := range evts[min:max] {
+			records = append(records, eventRecord(e))
+		}
+
+		return writeRecords(format, records)
+	}
+
+	err = writeEventsList(evts[min:max], cliCtx.Bool("verbose"))
 	if err != nil {
 		return cli.NewExitError("Could not print activity eventsList: "+err.Error(), -1)
 	}
@@ -76,18 +179,153 @@ func eventsList(cliCtx *cli.Context) error {
 	return nil
 }
 
-// writeEventsList prints the state of a event and returns an error if it occurs
-func writeEventsList(cliCtx *cli.Context, evts []*events.Event) error {
-	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
+// eventScope returns the scope events are listed for, the team or the user
+// when --me is set. The team is nil for users.
+func eventScope(ctx context.Context, cliCtx *cli.Context) (manifold.ID, *manifold.ID, error) {
+	if cliCtx.Bool("me") {
+		userID, err := loadUserID(ctx)
+		if err != nil {
+			return manifold.ID{}, nil, err
+		}
+		return *userID, nil, nil
+	}
+
+	teamID, err := validateTeamID(cliCtx)
+	if err != nil {
+		return manifold.ID{}, nil, err
+	}
+
+	return *teamID, teamID, nil
+}
+
+// eventFilter builds the filter requested through the flags. Projects and
+// resources are resolved to their ID when they still exist, or matched by
+// name otherwise, as deleted ones remain in the activity log.
+func eventFilter(ctx context.Context, cliCtx *cli.Context, client *api.API,
+	teamID *manifold.ID) (*activity.Filter, error) {
+	filter := &activity.Filter{Actors: cliCtx.StringSlice("actor")}
+
+	for _, v := range cliCtx.StringSlice("type") {
+		t, err := activity.ParseType(v)
+		if err != nil {
+			return nil, cli.NewExitError(err.Error(), -1)
+		}
+		filter.Types = append(filter.Types, t)
+	}
+
+	for _, v := range cliCtx.StringSlice("source") {
+		s, err := activity.ParseSource(v)
+		if err != nil {
+			return nil, cli.NewExitError(err.Error(), -1)
+		}
+		filter.Sources = append(filter.Sources, s)
+	}
+
+	now := time.Now()
+	var err error
+	if v := cliCtx.String("since"); v != "" {
+		filter.Since, err = activity.ParseTime(v, now)
+		if err != nil {
+			return nil, cli.NewExitError(err.Error(), -1)
+		}
+	}
+	if v := cliCtx.String("until"); v != "" {
+		filter.Until, err = activity.ParseTime(v, now)
+		if err != nil {
+			return nil, cli.NewExitError(err.Error(), -1)
+		}
+	}
+
+	if label := cliCtx.String("for-project"); label != "" {
+		filter.Projects = []string{label}
+
+		p, err := clients.FetchProjectByLabel(ctx, client.Marketplace, teamID, label)
+		if err == nil {
+			filter.Projects = append(filter.Projects, p.ID.String(), string(p.Body.Name))
+		}
+	}
+
+	if label := cliCtx.String("resource"); label != "" {
+		filter.Resources = []string{label}
+
+		resources, err := clients.FetchResources(ctx, client.Marketplace, teamID, "")
+		if err != nil {
+			return nil, cli.NewExitError("Could not retrieve resources: "+err.Error(), -1)
+		}
+		for _, r := range resources {
+			if string(r.Body.Label) == label {
+				filter.Resources = append(filter.Resources, r.ID.String(), string(r.Body.Name))
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// followEvents prints the most recent events, then polls for new ones until
+// interrupted.
+func followEvents(cliCtx *cli.Context, format output.Format, evts []*events.Event,
+	fetch func() ([]*events.Event, error)) error {
+
+	tail := activity.NewTail()
+	evts = tail.Next(evts)
+
+	_, n := limitCollection(len(evts), cliCtx.Int("limit"), 0)
+	evts = evts[len(evts)-n:]
 
-	limit := cliCtx.Int("limit")
-	offset := cliCtx.Int("offset")
 	verbose := cliCtx.Bool("verbose")
+	write := func(evts []*events.Event) error {
+		if !format.IsMachine() {
+			return writeEventsList(evts, verbose)
+		}
+
+		for _, e := range evts {
+			// Every event is its own document so they can be consumed as
+			// they are streamed
+			if format == output.YAML {
+				fmt.Println("---")
+			}
+			if err := writeRecords(format, eventRecord(e)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := write(evts); err != nil {
+		return err
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	ticker := time.NewTicker(cliCtx.Duration("interval"))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sigs:
+			return nil
+		case <-ticker.C:
+			next, err := fetch()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Could not retrieve events: %s\n", err)
+				continue
+			}
+
+			if err := write(tail.Next(next)); err != nil {
+				return err
+			}
+		}
+	}
+}
 
-	min, max := limitCollection(len(evts), limit, offset)
+// writeEventsList prints the state of a event and returns an error if it occurs
+func writeEventsList(evts []*events.Event, verbose bool) error {
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
 
-	for i := min; i < max; i++ {
-		e := evts[i]
+	for _, e := range evts {
 
 		fmt.Fprintln(w)
 		fmt.Fprintln(w, fmt.Sprintf("%s\t%s", color.Faint("ID"), e.ID))
@@ -160,6 +398,63 @@ func writeEventsList(cliCtx *cli.Context, evts []*events.Event) error {
 	return nil
 }
 
+// eventRecord returns the machine readable representation of an event.
+func eventRecord(e *events.Event) output.Event {
+	record := output.Event{
+		ID:      e.ID.String(),
+		Type:    string(e.Body.Type()),
+		ActorID: e.Body.ActorID().String(),
+		ScopeID: e.Body.ScopeID().String(),
+		IP:      e.Body.IPAddress(),
+	}
+
+	if actor := e.Body.Actor(); actor != nil {
+		record.Actor = actor.Name
+	}
+
+	if scope := e.Body.Scope(); scope != nil {
+		record.Scope = scope.Name
+	}
+
+	if source := e.Body.Source(); source != nil {
+		record.Source = *source
+	}
+
+	if cat := e.Body.CreatedAt(); cat != nil {
+		record.CreatedAt = time.Time(*cat).Format(time.RFC3339)
+	}
+
+	switch body := e.Body.(type) {
+	case *events.OperationProvisioned:
+		recordEventData(&record, body.Data.Resource, body.Data.Project, body.Data.Product, body.Data.Plan)
+	case *events.OperationDeprovisioned:
+		recordEventData(&record, body.Data.Resource, body.Data.Project, body.Data.Product, body.Data.Plan)
+	case *events.OperationResized:
+		recordEventData(&record, body.Data.Resource, body.Data.Project, body.Data.Product, body.Data.NewPlan)
+		if body.Data.OldPlan != nil {
+			record.OldPlan = body.Data.OldPlan.Name
+		}
+	}
+
+	return record
+}
+
+func recordEventData(record *output.Event, resource *events.Resource, project *events.Project,
+	product *events.Product, plan *events.Plan) {
+	if resource != nil {
+		record.Resource = resource.Name
+	}
+	if project != nil {
+		record.Project = project.Name
+	}
+	if product != nil {
+		record.Product = product.Name
+	}
+	if plan != nil {
+		record.Plan = plan.Name
+	}
+}
+
 func printResource(w io.Writer, resource *events.Resource, verbose bool) {
 	if resource != nil {
 		fmt.Fprintln(w, fmt.Sprintf("\t%s\t%s", color.Faint("Resource"), resource.Name))
@@ -12,6 +12,7 @@ func TestLimitCollection(t *testing.T) {
 		{length: 10, limit: 10, offset: 10, min: 0, max: 0},
 		{length: 10, limit: 50, offset: 0, min: 0, max: 10},
 		{length: 0, limit: 10, offset: 5, min: 0, max: 0},
+		{length: 10, limit: -1, offset: 0, min: 0, max: 0},
 	}
 
 	for _, tc := range tcs {
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"sort"
 	"strings"
 
 	"github.com/manifoldco/go-manifold"
@@ -14,6 +13,7 @@ import (
 
 	"github.com/manifoldco/manifold-cli/api"
 	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/credcache"
 	"github.com/manifoldco/manifold-cli/middleware"
 	"github.com/manifoldco/manifold-cli/prompts"
 
@@ -31,11 +31,12 @@ func init() {
 		Name:     "export",
 		Usage:    "Export all environment variables from all resources",
 		Category: "CONFIGURATION",
-		Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
-			middleware.LoadTeamPrefs, export),
+		Action: middleware.Chain(middleware.LoadDirPrefs, unlessOffline(middleware.EnsureSession),
+			unlessOffline(middleware.LoadTeamPrefs), export),
 		Flags: append(teamFlags, []cli.Flag{
 			formatFlag(formats[0], formatFlagStr),
 			projectFlag(),
+			offlineFlag(),
 		}...),
 	}
 
@@ -60,39 +61,84 @@ func export(cliCtx *cli.Context) error {
 		return err
 	}
 
+	var resources []credcache.Resource
+	if cliCtx.Bool("offline") {
+		e, err := cachedCredentials(cliCtx, projectName)
+		if err != nil {
+			return err
+		}
+
+		resources = e.Resources
+	} else {
+		resources, err = fetchExportCredentials(ctx, teamID, projectName, format)
+		if err != nil {
+			return err
+		}
+
+		storeCredentials(cliCtx, projectName, resources)
+	}
+
+	err = writeCredentials(os.Stdout, format, resources)
+	if err != nil {
+		return cli.NewExitError("Could not output to format: "+err.Error(), -1)
+	}
+
+	return nil
+}
+
+// writeCredentials renders the credentials of resources in one of formats.
+func writeCredentials(w io.Writer, format string, resources []credcache.Resource) error {
+	switch format {
+	case "env":
+		return writeFormat(w, resources, "%s=%s\n")
+	case "bash":
+		return writeFormat(w, resources, "export %s=%s\n")
+	case "powershell":
+		return writeFormat(w, resources, "$Env:%s = \"%s\"\n")
+	case "cmd":
+		return writeFormat(w, resources, "set %s=%s\n")
+	case "fish":
+		return writeFormat(w, resources, "set -x %s %s;\n")
+	case "json":
+		return writeJSON(w, resources)
+	default:
+		return cli.NewExitError("Unrecognized format value: "+format, -1)
+	}
+}
+
+// fetchExportCredentials returns the credentials of the project's resources,
+// or of the resources outside of projects when no project is given.
+func fetchExportCredentials(ctx context.Context, teamID *manifold.ID, projectName,
+	format string) ([]credcache.Resource, error) {
 	client, err := api.New(api.Analytics, api.Marketplace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// we need to fetch all the credentials for the rMap for resource naming, etc
+	// we need to fetch all the resources for resource naming, etc
 	prompts.SpinStart("Fetching Resources")
 	resources, err := clients.FetchResources(ctx, client.Marketplace, teamID, projectName)
 	prompts.SpinStop()
 	if err != nil {
-		return cli.NewExitError("Could not retrieve resources: "+err.Error(), -1)
+		return nil, cli.NewExitError("Could not retrieve resources: "+err.Error(), -1)
 	}
 
-	sort.Slice(resources, func(i, j int) bool {
-		return resources[i].Body.Name < resources[j].Body.Name
-	})
-
 	cMap := make(map[manifold.ID][]*models.Credential)
 	if projectName != "" {
 		p, err := clients.FetchProjectByLabel(ctx, client.Marketplace, teamID, projectName)
 		if err != nil {
-			return cli.NewExitError(fmt.Sprintf("Could not retrieve project: %s", err), -1)
+			return nil, cli.NewExitError(fmt.Sprintf("Could not retrieve project: %s", err), -1)
 		}
 
 		cMap, err = fetchProjectCredentials(ctx, client.Marketplace, p, true)
 		if err != nil {
-			return cli.NewExitError(fmt.Sprintf("Could not retrieve credentials: %s", err), -1)
+			return nil, cli.NewExitError(fmt.Sprintf("Could not retrieve credentials: %s", err), -1)
 		}
 	} else {
 		resources = filterResourcesWithoutProjects(resources)
 		cMap, err = fetchResourceCredentials(ctx, client.Marketplace, resources, true)
 		if err != nil {
-			return cli.NewExitError("Could not retrieve credentials: "+err.Error(), -1)
+			return nil, cli.NewExitError("Could not retrieve credentials: "+err.Error(), -1)
 		}
 	}
 
@@ -105,30 +151,7 @@ func export(cliCtx *cli.Context) error {
 
 	client.Analytics.Track(ctx, "Fetch Credentials", &params)
 
-	rMap := indexResources(resources)
-	w := os.Stdout
-	switch format {
-	case "env":
-		err = writeFormat(w, rMap, cMap, "%s=%s\n")
-	case "bash":
-		err = writeFormat(w, rMap, cMap, "export %s=%s\n")
-	case "powershell":
-		err = writeFormat(w, rMap, cMap, "$Env:%s = \"%s\"\n")
-	case "cmd":
-		err = writeFormat(w, rMap, cMap, "set %s=%s\n")
-	case "fish":
-		err = writeFormat(w, rMap, cMap, "set -x %s %s;\n")
-	case "json":
-		err = writeJSON(w, cMap)
-	default:
-		return cli.NewExitError("Unrecognized format value: "+format, -1)
-	}
-
-	if err != nil {
-		cli.NewExitError("Could not output to format: "+err.Error(), -1)
-	}
-
-	return nil
+	return cacheResources(resources, cMap), nil
 }
 
 func validFormat(format string) bool {
@@ -141,15 +164,11 @@ func validFormat(format string) bool {
 	return false
 }
 
-func writeFormat(w io.Writer, rMap map[manifold.ID]*models.Resource,
-	cMap map[manifold.ID][]*models.Credential, format string) error {
-	for rID, credentials := range cMap {
-		resource := rMap[rID]
-		fmt.Fprintf(w, "# %s\n", resource.Body.Name)
-		for _, c := range credentials {
-			for name, value := range c.Body.Values {
-				fmt.Fprintf(w, format, name, value)
-			}
+func writeFormat(w io.Writer, resources []credcache.Resource, format string) error {
+	for _, r := range resources {
+		fmt.Fprintf(w, "# %s\n", r.Name)
+		for name, value := range r.Values {
+			fmt.Fprintf(w, format, name, value)
 		}
 
 		fmt.Fprintf(w, "\n")
@@ -158,13 +177,8 @@ func writeFormat(w io.Writer, rMap map[manifold.ID]*models.Resource,
 	return nil
 }
 
-func writeJSON(w io.Writer, cMap map[manifold.ID][]*models.Credential) error {
-	credentials, err := flattenCMap(cMap)
-	if err != nil {
-		return err
-	}
-
-	b, err := json.MarshalIndent(credentials, "", "    ")
+func writeJSON(w io.Writer, resources []credcache.Resource) error {
+	b, err := json.MarshalIndent(credcache.Flatten(resources), "", "    ")
 	if err != nil {
 		return err
 	}
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/urfave/cli"
 
+	"github.com/manifoldco/manifold-cli/output"
 	"github.com/manifoldco/manifold-cli/placeholder"
 )
 
@@ -12,6 +13,47 @@ func formatFlag(defaultValue, description string) cli.Flag {
 	return placeholder.New("format, f", "FORMAT", description, defaultValue, "MANIFOLD_FORMAT", false)
 }
 
+func outputFlag() cli.Flag {
+	return placeholder.New("output", "FORMAT", "Render output as table, json or yaml",
+		string(output.Table), "MANIFOLD_OUTPUT", false)
+}
+
+func nonInteractiveFlag() cli.Flag {
+	return cli.BoolFlag{
+		Name:   "non-interactive",
+		Usage:  "Fail instead of prompting for missing values, set when stdin is not a terminal",
+		EnvVar: "MANIFOLD_NON_INTERACTIVE",
+	}
+}
+
+func profileFlag() cli.Flag {
+	return cli.StringFlag{
+		Name:   "profile",
+		Usage:  "Use the credentials and settings of this profile",
+		EnvVar: "MANIFOLD_PROFILE",
+	}
+}
+
+func debugFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:   "debug",
+			Usage:  "Trace API requests to stderr, with secrets redacted",
+			EnvVar: "MANIFOLD_DEBUG",
+		},
+		cli.StringFlag{
+			Name:   "debug-file",
+			Usage:  "Trace API requests to this file instead of stderr, implies --debug",
+			EnvVar: "MANIFOLD_DEBUG_FILE",
+		},
+		cli.BoolFlag{
+			Name:   "debug-bodies",
+			Usage:  "Include request and response bodies in traces, implies --debug",
+			EnvVar: "MANIFOLD_DEBUG_BODIES",
+		},
+	}
+}
+
 func descriptionFlag() cli.Flag {
 	return cli.StringFlag{
 		Name:   "description, d",
@@ -66,7 +108,7 @@ func planFlag() cli.Flag {
 func regionFlag() cli.Flag {
 	return cli.StringFlag{
 		Name:   "region",
-		Usage:  "Use this region",
+		Usage:  "Use this region, by name or platform::location",
 		EnvVar: "MANIFOLD_REGION",
 	}
 }
@@ -144,3 +186,11 @@ func verboseFlag() cli.Flag {
 		Usage: "Make the command more talkative",
 	}
 }
+
+func offlineFlag() cli.Flag {
+	return cli.BoolFlag{
+		Name:   "offline",
+		Usage:  "Only serve credentials from the local cache, without contacting Manifold",
+		EnvVar: "MANIFOLD_OFFLINE",
+	}
+}
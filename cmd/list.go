@@ -15,8 +15,10 @@ import (
 	"github.com/manifoldco/manifold-cli/config"
 	"github.com/manifoldco/manifold-cli/data/catalog"
 	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/output"
 	"github.com/manifoldco/manifold-cli/session"
 
+	cModels "github.com/manifoldco/manifold-cli/generated/catalog/models"
 	"github.com/manifoldco/manifold-cli/generated/marketplace/models"
 	pModels "github.com/manifoldco/manifold-cli/generated/provisioning/models"
 )
@@ -42,6 +44,7 @@ func init() {
 			middleware.LoadTeamPrefs, list),
 		Flags: append(teamFlags, []cli.Flag{
 			projectFlag(),
+			outputFlag(),
 		}...),
 	}
 
@@ -92,6 +95,24 @@ func list(cliCtx *cli.Context) error {
 		return err
 	}
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		records := make([]output.Resource, 0)
+		for _, group := range list.groups {
+			for _, resource := range group.resources {
+				record, err := resourceRecord(ctx, catalog, resource, statuses)
+				if err != nil {
+					return err
+				}
+
+				record.Owner = group.owner
+				record.Project = group.project
+				records = append(records, record)
+			}
+		}
+
+		return writeRecords(format, records)
+	}
+
 	fmt.Printf("%d resources in %d projects\n", list.totalResources, list.totalProjects)
 	fmt.Println("Use `manifold view [resource-name]` to display resource details")
 
@@ -119,26 +140,9 @@ func list(cliCtx *cli.Context) error {
 			rType := "Custom"
 
 			if *resource.Body.Source != "custom" {
-				// Get catalog data
-				product, err := catalog.GetProduct(*resource.Body.ProductID)
+				product, plan, err := resourcePlan(ctx, catalog, resource)
 				if err != nil {
-					return cli.NewExitError("Product referenced by resource does not exist: "+
-						err.Error(), -1)
-				}
-				if product == nil {
-					return cli.NewExitError("Product not found", -1)
-				}
-				plan, err := catalog.GetPlan(*resource.Body.PlanID)
-				if err != nil {
-					// Try and get unlisted plan not in local cache
-					plan, err = catalog.FetchPlanById(ctx, *resource.Body.PlanID)
-					if err != nil {
-						return cli.NewExitError("Plan referenced by resource does not exist: "+
-							err.Error(), -1)
-					}
-				}
-				if plan == nil {
-					return cli.NewExitError("Product not found", -1)
+					return err
 				}
 
 				rType = fmt.Sprintf("%s %s", product.Body.Name, plan.Body.Name)
@@ -157,6 +161,71 @@ func list(cliCtx *cli.Context) error {
 	return nil
 }
 
+// resourcePlan returns the catalog product and plan referenced by a non-custom
+// resource.
+func resourcePlan(ctx context.Context, cat *catalog.Catalog, resource *models.Resource) (
+	*cModels.Product, *cModels.Plan, error) {
+	product, err := cat.GetProduct(*resource.Body.ProductID)
+	if err != nil {
+		return nil, nil, cli.NewExitError("Product referenced by resource does not exist: "+
+			err.Error(), -1)
+	}
+	if product == nil {
+		return nil, nil, cli.NewExitError("Product not found", -1)
+	}
+
+	plan, err := cat.GetPlan(*resource.Body.PlanID)
+	if err != nil {
+		// Try and get unlisted plan not in local cache
+		plan, err = cat.FetchPlanById(ctx, *resource.Body.PlanID)
+		if err != nil {
+			return nil, nil, cli.NewExitError("Plan referenced by resource does not exist: "+
+				err.Error(), -1)
+		}
+	}
+	if plan == nil {
+		return nil, nil, cli.NewExitError("Plan not found", -1)
+	}
+
+	return product, plan, nil
+}
+
+// resourceRecord returns the machine readable representation of a resource.
+// Owner and project are left for the caller to fill in.
+func resourceRecord(ctx context.Context, cat *catalog.Catalog, resource *models.Resource,
+	statuses map[manifold.ID]string) (output.Resource, error) {
+	record := output.Resource{
+		ID:     resource.ID.String(),
+		Name:   string(resource.Body.Label),
+		Title:  string(resource.Body.Name),
+		Custom: true,
+		Status: "Ready",
+	}
+
+	if status, ok := statuses[resource.ID]; ok {
+		record.Status = status
+	}
+
+	if *resource.Body.Source == "custom" {
+		return record, nil
+	}
+
+	product, plan, err := resourcePlan(ctx, cat, resource)
+	if err != nil {
+		return record, err
+	}
+
+	record.Custom = false
+	record.Product = string(product.Body.Label)
+	record.Plan = string(plan.Body.Label)
+
+	if region, err := cat.GetRegion(*resource.Body.RegionID); err == nil {
+		record.Region = string(region.Body.Name)
+	}
+
+	return record, nil
+}
+
 func buildResourceList(resources []*models.Resource, operations []*pModels.Operation) (
 	[]*models.Resource, map[manifold.ID]string) {
 	out := []*models.Resource{}
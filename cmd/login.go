@@ -45,6 +45,13 @@ func login(_ *cli.Context) error {
 		return errs.ErrAlreadyLoggedIn
 	}
 
+	if prompts.NonInteractive {
+		return &errs.NonInteractiveError{
+			Prompt: "your email and password",
+			Flag:   session.EnvManifoldEmail + " and " + session.EnvManifoldPass,
+		}
+	}
+
 	email, err := prompts.Email("")
 	if err != nil {
 		return err
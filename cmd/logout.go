@@ -51,6 +51,15 @@ func logout(_ *cli.Context) error {
 		return cli.NewExitError("Failed to logout: "+err.Error(), -1)
 	}
 
+	// Cached credentials belong to the session, don't leave them behind
+	cache, err := loadCredentialCache(cfg)
+	if err != nil {
+		return err
+	}
+	if err := cache.ClearProfile(cfg.Profile); err != nil {
+		return cli.NewExitError("Could not clear cache: "+err.Error(), -1)
+	}
+
 	fmt.Printf("You are now logged out!\n")
 	return nil
 }
@@ -8,7 +8,11 @@ import (
 
 	"github.com/manifoldco/go-manifold"
 	"github.com/manifoldco/go-manifold/names"
+	"github.com/manifoldco/manifold-cli/clients"
 	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/data/catalog"
+	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/output"
 	"github.com/manifoldco/manifold-cli/plugins"
 	"github.com/manifoldco/manifold-cli/prompts"
 )
@@ -25,9 +29,15 @@ func main() {
 	app.HelpName = "manifold"
 	app.Usage = "A tool making it easy to buy, manage, and integrate developer services into an application."
 	app.Version = config.Version
-	app.Commands = append(cmds, helpCommand)
-	app.Flags = append(app.Flags, cli.HelpFlag)
+	app.Commands = withCompletion(withOutput(append(cmds, helpCommand)))
+	app.Flags = append(app.Flags, cli.HelpFlag, outputFlag(), nonInteractiveFlag(),
+		profileFlag())
+	app.Flags = append(app.Flags, debugFlags()...)
 	app.EnableBashCompletion = true
+	app.BashComplete = completeArgs
+	app.Before = middleware.Chain(loadOutputFormat, loadNonInteractive, loadProfile,
+		loadDebug)
+	catalog.Refresh = refreshCatalogDetached
 
 	app.Action = func(cliCtx *cli.Context) error {
 		// Show help if no arguments passed
@@ -38,11 +48,19 @@ func main() {
 
 		// Execute plugin if installed
 		cmd := os.Args[1]
-		success, err := plugins.Run(cmd)
+		name, err := plugins.Lookup(cmd)
 		if err != nil {
 			return cli.NewExitError("Plugin error: "+err.Error(), -1)
 		}
-		if success {
+		if name != "" {
+			pluginCtx, err := pluginContext()
+			if err != nil {
+				return cli.NewExitError("Plugin error: "+err.Error(), -1)
+			}
+
+			if _, err := plugins.Run(cmd, pluginCtx); err != nil {
+				return cli.NewExitError("Plugin error: "+err.Error(), -1)
+			}
 			return nil
 		}
 
@@ -55,6 +73,61 @@ func main() {
 	app.Run(os.Args)
 }
 
+// loadOutputFormat validates the --output flag, globally or for a command.
+// Machine readable formats disable spinners so stdout only contains the
+// rendered records.
+func loadOutputFormat(cliCtx *cli.Context) error {
+	format, err := output.Parse(cliCtx.String("output"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), -1)
+	}
+
+	if format.IsMachine() {
+		prompts.IsInteractive = false
+	}
+
+	return nil
+}
+
+// loadNonInteractive disables prompts when --non-interactive is set. Prompts
+// are already disabled when stdin is not a terminal.
+func loadNonInteractive(cliCtx *cli.Context) error {
+	if cliCtx.Bool("non-interactive") {
+		prompts.NonInteractive = true
+	}
+
+	return nil
+}
+
+// loadProfile selects the profile given through --profile or
+// MANIFOLD_PROFILE for every config loaded by this invocation.
+func loadProfile(cliCtx *cli.Context) error {
+	config.UseProfile(cliCtx.String("profile"))
+	return nil
+}
+
+// loadDebug traces API requests to stderr, or to the file given through
+// --debug-file, when debugging is enabled.
+func loadDebug(cliCtx *cli.Context) error {
+	file := cliCtx.String("debug-file")
+	bodies := cliCtx.Bool("debug-bodies")
+	if !cliCtx.Bool("debug") && file == "" && !bodies {
+		return nil
+	}
+
+	w := os.Stderr
+	if file != "" {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return cli.NewExitError("Could not open debug file: "+err.Error(), -1)
+		}
+		w = f
+	}
+
+	clients.EnableTracing(w, bodies)
+	return nil
+}
+
 // copied from urfave/cli so we can set the category
 var helpCommand = cli.Command{
 	Name:      "help",
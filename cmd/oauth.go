@@ -55,7 +55,10 @@ func oauth(cliCtx *cli.Context) error {
 
 	if s.Authenticated() {
 		// link
-		_, err := prompts.Confirm("Do you wish to link your GitHub account to Manifold")
+		_, err := prompts.Confirm("Do you wish to link your GitHub account to Manifold", "")
+		if _, ok := err.(*errs.NonInteractiveError); ok {
+			return err
+		}
 		if err != nil {
 			return cli.NewExitError(fmt.Sprintf("Could not link accounts: %s", err), -1)
 		}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/juju/ansiterm"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/credcache"
+	"github.com/manifoldco/manifold-cli/data/catalog"
+	"github.com/manifoldco/manifold-cli/errs"
+	"github.com/manifoldco/manifold-cli/generated/catalog/models"
+	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/output"
+	"github.com/manifoldco/manifold-cli/prompts"
+)
+
+// OAuth clients are exported under these names by `oauth-clients create
+// --format`.
+const (
+	oauthClientIDName     = "MANIFOLD_CLIENT_ID"
+	oauthClientSecretName = "MANIFOLD_CLIENT_SECRET"
+)
+
+func init() {
+	oauthClientsCmd := cli.Command{
+		Name:     "oauth-clients",
+		Usage:    "Manage the OAuth clients your products use to access the Connector API",
+		Category: "AUTHENTICATION",
+		Subcommands: []cli.Command{
+			{
+				Name:  "create",
+				Usage: "Create an OAuth client for a product, showing its secret once",
+				Flags: []cli.Flag{
+					productFlag(), providerFlag(), descriptionFlag(), outputFlag(),
+					formatFlag("", fmt.Sprintf("Export the client in this format instead (%s)",
+						strings.Join(formats, ", "))),
+				},
+				Action: middleware.Chain(middleware.EnsureSession, createOAuthClientCmd),
+			},
+			{
+				Name:   "list",
+				Usage:  "List the OAuth clients of a product",
+				Flags:  []cli.Flag{productFlag(), providerFlag(), outputFlag()},
+				Action: middleware.Chain(middleware.EnsureSession, listOAuthClientsCmd),
+			},
+			{
+				Name:      "delete",
+				ArgsUsage: "[client-id]",
+				Usage:     "Delete an OAuth client of a product, revoking its access",
+				Flags:     []cli.Flag{productFlag(), providerFlag(), yesFlag()},
+				Action:    middleware.Chain(middleware.EnsureSession, deleteOAuthClientCmd),
+			},
+		},
+	}
+
+	cmds = append(cmds, oauthClientsCmd)
+}
+
+func createOAuthClientCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	format := cliCtx.String("format")
+	if format != "" && !validFormat(format) {
+		return cli.NewExitError("You provided an invalid format!", -1)
+	}
+
+	client, err := api.New(api.Catalog, api.Connector)
+	if err != nil {
+		return err
+	}
+
+	product, err := oauthProduct(ctx, cliCtx, client)
+	if err != nil {
+		return err
+	}
+
+	desc := cliCtx.String("description")
+	if desc == "" {
+		desc, err = prompts.OAuthClientDescription()
+		if err != nil {
+			return prompts.HandleSelectError(err, "Failed to describe OAuth client")
+		}
+	}
+	if len(desc) < 3 || len(desc) > 256 {
+		return cli.NewExitError("The description must be between 3 and 256 characters", -1)
+	}
+
+	cred, err := clients.CreateOAuthCredential(ctx, client.Connector, product.ID, desc)
+	if err != nil {
+		return cli.NewExitError("Could not create OAuth client: "+err.Error(), -1)
+	}
+
+	if format != "" {
+		return writeCredentials(os.Stdout, format, []credcache.Resource{{
+			Name: fmt.Sprintf("%s OAuth client (%s)", product.Body.Label, cred.Description),
+			Values: map[string]string{
+				oauthClientIDName:     cred.ID.String(),
+				oauthClientSecretName: cred.Secret,
+			},
+		}})
+	}
+
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		return writeRecords(format, oauthClientRecord(cred, product))
+	}
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Client ID"), cred.ID)
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Client Secret"), color.Bold(cred.Secret))
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Product"), product.Body.Label)
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Description"), cred.Description)
+	w.Flush()
+
+	fmt.Println("")
+	fmt.Println("Be sure to save your client secret in a safe place! We won't be able to show you it again.")
+	return nil
+}
+
+func listOAuthClientsCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Catalog, api.Connector)
+	if err != nil {
+		return err
+	}
+
+	product, err := oauthProduct(ctx, cliCtx, client)
+	if err != nil {
+		return err
+	}
+
+	prompts.SpinStart("Fetching OAuth Clients")
+	creds, err := clients.FetchOAuthCredentials(ctx, client.Connector, product.ID)
+	prompts.SpinStop()
+	if err != nil {
+		return cli.NewExitError("Could not retrieve OAuth clients: "+err.Error(), -1)
+	}
+
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		records := make([]output.OAuthClient, len(creds))
+		for i, c := range creds {
+			records[i] = oauthClientRecord(c, product)
+		}
+
+		return writeRecords(format, records)
+	}
+
+	fmt.Printf("%d OAuth clients found for %s\n", len(creds), product.Body.Label)
+	if len(creds) == 0 {
+		fmt.Println("Use `manifold oauth-clients create` to create a client")
+		return nil
+	}
+	fmt.Println("Use `manifold oauth-clients delete [client-id]` to revoke a client")
+	fmt.Println("")
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
+	w.SetStyle(ansiterm.Bold)
+	fmt.Fprintln(w, "Client ID\tDescription\tCreated")
+	w.Reset()
+	for _, c := range creds {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.ID, c.Description,
+			color.Faint(c.CreatedAt.Local().Format("2006-01-02 15:04")))
+	}
+
+	return w.Flush()
+}
+
+func deleteOAuthClientCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+	id, err := optionalArgID(cliCtx, 0, "client")
+	if err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Catalog, api.Connector)
+	if err != nil {
+		return err
+	}
+
+	// Without a client ID, select one of the clients of the product
+	if id == nil {
+		if cliCtx.String("product") == "" {
+			return errs.NewUsageExitError(cliCtx, cli.NewExitError(
+				"Provide a client ID, or --product to select one of its clients", -1))
+		}
+
+		product, err := oauthProduct(ctx, cliCtx, client)
+		if err != nil {
+			return err
+		}
+
+		creds, err := clients.FetchOAuthCredentials(ctx, client.Connector, product.ID)
+		if err != nil {
+			return cli.NewExitError("Could not retrieve OAuth clients: "+err.Error(), -1)
+		}
+		if len(creds) == 0 {
+			return cli.NewExitError(fmt.Sprintf("No OAuth clients found for %s", product.Body.Label), -1)
+		}
+
+		labels := make([]string, len(creds))
+		for i, c := range creds {
+			labels[i] = fmt.Sprintf("%s - %s", c.ID, c.Description)
+		}
+
+		idx, err := prompts.SelectOAuthClient(labels)
+		if err != nil {
+			return prompts.HandleSelectError(err, "Failed to select OAuth client")
+		}
+		id = &creds[idx].ID
+	}
+
+	if !cliCtx.Bool("yes") {
+		_, err = prompts.Confirm("Are you sure you want to revoke this OAuth client? It cannot be undone", "--yes")
+		if _, ok := err.(*errs.NonInteractiveError); ok {
+			return err
+		}
+		if err != nil {
+			return cli.NewExitError("OAuth client not revoked", -1)
+		}
+	}
+
+	if err := clients.DeleteOAuthCredential(ctx, client.Connector, *id); err != nil {
+		return cli.NewExitError("Could not delete OAuth client: "+err.Error(), -1)
+	}
+
+	fmt.Println("Your OAuth client has been revoked, you can now discard its secret.")
+	return nil
+}
+
+// oauthProduct returns the product named by --product, which can be narrowed
+// to a provider with --provider. Products of a provider are fetched directly,
+// so those not listed in the catalog yet can be found.
+func oauthProduct(ctx context.Context, cliCtx *cli.Context, client *api.API) (*models.Product, error) {
+	productName, err := requiredName(cliCtx, "product")
+	if err != nil {
+		return nil, err
+	}
+
+	providerName, err := validateName(cliCtx, "provider")
+	if err != nil {
+		return nil, err
+	}
+
+	if providerName != "" {
+		provider, err := client.FetchProvider(providerName)
+		if err != nil {
+			return nil, cli.NewExitError(err, -1)
+		}
+
+		product, err := client.FetchProduct(productName, provider.ID.String())
+		if err != nil {
+			return nil, cli.NewExitError(err, -1)
+		}
+
+		return product, nil
+	}
+
+	cat, err := catalog.New(ctx, client.Catalog)
+	if err != nil {
+		return nil, cli.NewExitError("Could not load catalog: "+err.Error(), -1)
+	}
+
+	product, err := catalogProduct(cat, productName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, cli.NewExitError(fmt.Sprintf(
+			"Product %q not found, use --provider for products not listed yet", productName), -1)
+	}
+
+	return product, nil
+}
+
+func oauthClientRecord(c *clients.OAuthCredential, product *models.Product) output.OAuthClient {
+	return output.OAuthClient{
+		ID:          c.ID.String(),
+		Product:     string(product.Body.Label),
+		Description: c.Description,
+		Secret:      c.Secret,
+		CreatedAt:   c.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
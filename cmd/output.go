@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/output"
+)
+
+// outputFormat returns the format requested through the --output flag of the
+// command, or the global one. Values are validated before the command runs,
+// so Table is returned for anything unexpected.
+func outputFormat(cliCtx *cli.Context) output.Format {
+	name := cliCtx.GlobalString("output")
+	if cliCtx.IsSet("output") {
+		name = cliCtx.String("output")
+	}
+
+	format, err := output.Parse(name)
+	if err != nil {
+		return output.Table
+	}
+
+	return format
+}
+
+// withOutput validates the --output flag of every command rendering records,
+// recursively.
+func withOutput(commands []cli.Command) []cli.Command {
+	for i := range commands {
+		for _, f := range commands[i].Flags {
+			if f.GetName() == "output" {
+				commands[i].Before = loadOutputFormat
+			}
+		}
+		commands[i].Subcommands = withOutput(commands[i].Subcommands)
+	}
+
+	return commands
+}
+
+// writeRecords renders the given records to stdout using the requested
+// machine readable format.
+func writeRecords(format output.Format, records interface{}) error {
+	err := output.Write(os.Stdout, format, records)
+	if err != nil {
+		return cli.NewExitError("Could not render output: "+err.Error(), -1)
+	}
+
+	return nil
+}
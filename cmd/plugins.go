@@ -1,19 +1,31 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
 
+	"github.com/juju/ansiterm"
 	"github.com/urfave/cli"
 
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/config"
 	"github.com/manifoldco/manifold-cli/errs"
+	"github.com/manifoldco/manifold-cli/output"
 	"github.com/manifoldco/manifold-cli/plugins"
+	"github.com/manifoldco/manifold-cli/plugins/sdk"
 	"github.com/manifoldco/manifold-cli/prompts"
 )
 
 func init() {
+	versionFlag := cli.StringFlag{
+		Name:  "version",
+		Usage: "Pin the plugin to a tag, branch or commit of its repository",
+	}
+
 	pluginsCmd := cli.Command{
 		Name:     "plugins",
 		Usage:    "Manage installed plugins",
@@ -23,8 +35,43 @@ func init() {
 				Name:      "install",
 				Usage:     "Install a new plugin",
 				ArgsUsage: "[repository]",
+				Flags:     []cli.Flag{versionFlag},
 				Action:    install,
 			},
+			{
+				Name:   "list",
+				Usage:  "List installed plugins and whether their binary is verified",
+				Flags:  []cli.Flag{outputFlag()},
+				Action: listPlugins,
+			},
+			{
+				Name:      "info",
+				Usage:     "Show where a plugin was installed from and its recorded checksum",
+				ArgsUsage: "<plugin>",
+				Flags:     []cli.Flag{outputFlag()},
+				Action:    pluginInfo,
+			},
+			{
+				Name: "update",
+				Usage: "Update a plugin, or every plugin, to the latest commit or to its " +
+					"pinned version",
+				ArgsUsage: "[plugin]",
+				Flags: []cli.Flag{
+					versionFlag,
+					cli.BoolFlag{
+						Name:  "latest",
+						Usage: "Remove the version pin and follow the default branch",
+					},
+				},
+				Action: updatePlugins,
+			},
+			{
+				Name:      "remove",
+				Usage:     "Uninstall a plugin along with its configuration",
+				ArgsUsage: "<plugin>",
+				Flags:     []cli.Flag{yesFlag()},
+				Action:    removePlugin,
+			},
 		},
 	}
 
@@ -32,11 +79,6 @@ func init() {
 }
 
 func install(cliCtx *cli.Context) error {
-	pluginsDir, err := plugins.Path()
-	if err != nil {
-		return cli.NewExitError("Failed to install plugin: "+err.Error(), -1)
-	}
-
 	args := cliCtx.Args()
 	if len(args) < 1 {
 		return errs.NewUsageExitError(cliCtx, cli.NewExitError("Missing repository", -1))
@@ -44,29 +86,25 @@ func install(cliCtx *cli.Context) error {
 
 	// Identify the name of the plugin being installed
 	name := plugins.DeriveName(args[0])
-	if err != nil {
-		return cli.NewExitError(err.Error(), -1)
-	}
 	spin := prompts.NewSpinner(fmt.Sprintf("Installing plugin `%s`", name))
 	spin.Start()
 	defer spin.Stop()
 
-	// Abort if already exists
-	destinationDir := path.Join(pluginsDir, name)
-	if _, err := os.Stat(destinationDir); !os.IsNotExist(err) {
+	m, err := plugins.Install(args[0], cliCtx.String("version"))
+	if err == plugins.ErrAlreadyInstalled {
 		return cli.NewExitError("Plugin already installed.", -1)
 	}
+	if err != nil {
+		return cli.NewExitError("Failed to install plugin: "+err.Error(), -1)
+	}
 
-	// Clone to its destination
-	pluginURL := plugins.NormalizeURL(args[0])
-	cmd := exec.Command("git", "clone", pluginURL, destinationDir)
-	err = cmd.Run()
+	pluginsDir, err := plugins.Path()
 	if err != nil {
-		return cli.NewExitError("Failed to clone plugin: "+err.Error(), -1)
+		return cli.NewExitError("Failed to install plugin: "+err.Error(), -1)
 	}
 
 	// Initialize the config file
-	newFile, err := os.Create(path.Join(destinationDir, ".config.json"))
+	newFile, err := os.Create(path.Join(pluginsDir, m.Name, ".config.json"))
 	if err != nil {
 		return cli.NewExitError("Failed to create config file: "+err.Error(), -1)
 	}
@@ -76,10 +114,289 @@ func install(cliCtx *cli.Context) error {
 
 	// Finally output the Help text
 	exe := plugins.Shortname(name)
-	fmt.Printf("Plugin installed. Use `manifold %s` to execute.\n\n", exe)
+	fmt.Printf("Plugin installed at %s. Use `manifold %s` to execute.\n\n", pluginVersion(m), exe)
 	err = plugins.Help(name)
 	if err != nil {
 		return cli.NewExitError("Failed to display plugin help output: "+err.Error(), -1)
 	}
 	return nil
 }
+
+func listPlugins(cliCtx *cli.Context) error {
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	names, err := plugins.List()
+	if err != nil {
+		return cli.NewExitError("Failed to list plugins: "+err.Error(), -1)
+	}
+
+	records := make([]output.Plugin, 0, len(names))
+	for _, name := range names {
+		records = append(records, pluginRecord(name))
+	}
+
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		return writeRecords(format, records)
+	}
+
+	if len(records) == 0 {
+		fmt.Println("No plugins installed. Use `manifold plugins install <repository>` to add one.")
+		return nil
+	}
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	w.SetForeground(ansiterm.Gray)
+	fmt.Fprintln(w, "Command\tVersion\tCommit\tStatus")
+	w.Reset()
+
+	for _, r := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Command, displayPluginVersion(r),
+			shortCommit(r.Commit), pluginStatus(r.Status))
+	}
+
+	return w.Flush()
+}
+
+func pluginInfo(cliCtx *cli.Context) error {
+	if err := maxOptionalArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+
+	name, err := requiredPlugin(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	r := pluginRecord(name)
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		return writeRecords(format, r)
+	}
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Name"), r.Name)
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Command"), r.Command)
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Source"), r.Source)
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Version"), displayPluginVersion(r))
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Commit"), r.Commit)
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Checksum"), r.Checksum)
+	fmt.Fprintf(w, "%s\t%s\n", color.Faint("Status"), pluginStatus(r.Status))
+	return w.Flush()
+}
+
+func updatePlugins(cliCtx *cli.Context) error {
+	if err := maxOptionalArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+
+	version := cliCtx.String("version")
+	latest := cliCtx.Bool("latest")
+	if version != "" && latest {
+		return errs.NewUsageExitError(cliCtx,
+			cli.NewExitError("--version and --latest cannot be used together", -1))
+	}
+
+	var names []string
+	if cliCtx.NArg() > 0 {
+		name, err := requiredPlugin(cliCtx)
+		if err != nil {
+			return err
+		}
+		names = []string{name}
+	} else {
+		if version != "" {
+			return errs.NewUsageExitError(cliCtx,
+				cli.NewExitError("A plugin is required to pin a version", -1))
+		}
+
+		var err error
+		names, err = plugins.List()
+		if err != nil {
+			return cli.NewExitError("Failed to list plugins: "+err.Error(), -1)
+		}
+	}
+
+	for _, name := range names {
+		spin := prompts.NewSpinner(fmt.Sprintf("Updating plugin `%s`", name))
+		spin.Start()
+		m, err := plugins.Update(name, version, latest)
+		spin.Stop()
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("Failed to update plugin `%s`: %s", name, err), -1)
+		}
+
+		fmt.Printf("Plugin `%s` is at %s (%s)\n", plugins.Shortname(name), pluginVersion(m),
+			shortCommit(m.Commit))
+	}
+
+	return nil
+}
+
+func removePlugin(cliCtx *cli.Context) error {
+	if err := maxOptionalArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+
+	name, err := requiredPlugin(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	if !cliCtx.Bool("yes") {
+		_, err = prompts.Confirm(fmt.Sprintf("Are you sure you want to remove plugin `%s`", name), "--yes")
+		if _, ok := err.(*errs.NonInteractiveError); ok {
+			return err
+		}
+		if err != nil {
+			return cli.NewExitError("Plugin not removed", -1)
+		}
+	}
+
+	if err := plugins.Remove(name); err != nil {
+		return cli.NewExitError("Failed to remove plugin: "+err.Error(), -1)
+	}
+
+	fmt.Printf("Plugin `%s` removed.\n", name)
+	return nil
+}
+
+// requiredPlugin returns the directory name of the installed plugin given as
+// the first argument, by its name or by its command.
+func requiredPlugin(cliCtx *cli.Context) (string, error) {
+	arg := cliCtx.Args().First()
+	if arg == "" {
+		return "", errs.NewUsageExitError(cliCtx, cli.NewExitError("Missing plugin", -1))
+	}
+
+	names, err := plugins.List()
+	if err != nil {
+		return "", cli.NewExitError("Failed to list plugins: "+err.Error(), -1)
+	}
+
+	for _, name := range names {
+		if name == arg || plugins.Shortname(name) == arg {
+			return name, nil
+		}
+	}
+
+	return "", cli.NewExitError(fmt.Sprintf("Plugin `%s` is not installed", arg), -1)
+}
+
+func pluginRecord(name string) output.Plugin {
+	r := output.Plugin{Name: name, Command: plugins.Shortname(name)}
+
+	m, err := plugins.ReadManifest(name)
+	if err != nil {
+		r.Status = "unmanaged"
+		return r
+	}
+
+	r.Source = m.Source
+	r.Version = m.Version
+	r.Commit = m.Commit
+	r.Checksum = m.Checksum
+
+	r.Status = "verified"
+	if plugins.Verify(name) != nil {
+		r.Status = "modified"
+	}
+
+	return r
+}
+
+func pluginVersion(m *plugins.Manifest) string {
+	if m.Pinned() {
+		return m.Version
+	}
+
+	return "latest"
+}
+
+func displayPluginVersion(r output.Plugin) string {
+	switch {
+	case r.Version != "":
+		return r.Version
+	case r.Status == "unmanaged":
+		return "-"
+	default:
+		return "latest"
+	}
+}
+
+func pluginStatus(status string) string {
+	switch status {
+	case "verified":
+		return status
+	case "modified":
+		return color.Color(ansiterm.Red, "modified, reinstall or update it")
+	default:
+		return color.Color(ansiterm.Red, "unmanaged, update it before running it")
+	}
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	if commit == "" {
+		return "-"
+	}
+
+	return commit
+}
+
+// pluginContext returns the context handed off to plugins. The team of the
+// directory's .manifold.yml takes precedence over the one switched to, and is
+// only looked up when they differ. Plugins are still launched when the
+// directory's team can't be resolved, with no team in their context.
+func pluginContext() (*sdk.Context, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &sdk.Context{
+		CLIVersion: config.Version,
+		Profile:    cfg.Profile,
+		Hostname:   cfg.Hostname,
+		Scheme:     cfg.TransportScheme,
+		Token:      clients.RetrieveToken(cfg),
+	}
+
+	yaml, err := config.LoadYaml(true)
+	if err != nil {
+		return c, nil
+	}
+	c.Project = yaml.Project
+
+	if yaml.Team == "" || yaml.Team == cfg.TeamName {
+		if cfg.TeamID != "" {
+			c.Team = &sdk.Team{ID: cfg.TeamID, Label: cfg.TeamName, Title: cfg.TeamTitle}
+		}
+		return c, nil
+	}
+
+	client, err := api.New(api.Identity)
+	if err != nil {
+		return c, nil
+	}
+
+	teams, err := clients.FetchTeams(context.Background(), client.Identity)
+	if err != nil {
+		return c, nil
+	}
+
+	for _, t := range teams {
+		if string(t.Body.Label) == yaml.Team {
+			c.Team = &sdk.Team{
+				ID:    t.ID.String(),
+				Label: string(t.Body.Label),
+				Title: string(t.Body.Name),
+			}
+			return c, nil
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/juju/ansiterm"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/output"
+)
+
+func init() {
+	profileCmd := cli.Command{
+		Name:     "profile",
+		Usage:    "Manage named profiles, each with their own login and team",
+		Category: "CONFIGURATION",
+		Subcommands: []cli.Command{
+			{
+				Name:   "list",
+				Usage:  "List all profiles",
+				Flags:  []cli.Flag{outputFlag()},
+				Action: listProfilesCmd,
+			},
+			{
+				Name:      "use",
+				Usage:     "Use a profile when --profile and MANIFOLD_PROFILE aren't set",
+				ArgsUsage: "<name>",
+				Action:    useProfileCmd,
+			},
+			{
+				Name:      "add",
+				Usage:     "Add a logged out profile",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "hostname",
+						Usage: "Connect to Manifold at this hostname",
+					},
+					cli.StringFlag{
+						Name:  "scheme",
+						Usage: "Connect to Manifold over this scheme, such as http for a dev-server",
+					},
+				},
+				Action: addProfileCmd,
+			},
+			{
+				Name:      "remove",
+				Usage:     "Remove a profile along with its login",
+				ArgsUsage: "<name>",
+				Action:    removeProfileCmd,
+			},
+		},
+	}
+
+	cmds = append(cmds, profileCmd)
+}
+
+func listProfilesCmd(cliCtx *cli.Context) error {
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	names, active, err := config.Profiles()
+	if err != nil {
+		return cli.NewExitError("Could not load config: "+err.Error(), -1)
+	}
+
+	records := make([]output.Profile, 0, len(names))
+	for _, name := range names {
+		cfg, err := config.LoadProfile(name)
+		if err != nil {
+			return cli.NewExitError("Could not load profile: "+err.Error(), -1)
+		}
+
+		records = append(records, output.Profile{
+			Name:     name,
+			Hostname: cfg.Hostname,
+			Team:     cfg.TeamName,
+			LoggedIn: cfg.AuthToken != "",
+			Active:   name == active,
+		})
+	}
+
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		return writeRecords(format, records)
+	}
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
+
+	fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", color.Bold("Name"), color.Bold("Hostname"),
+		color.Bold("Team"), color.Bold("Status"))
+
+	for _, p := range records {
+		marker := " "
+		if p.Active {
+			marker = "*"
+		}
+
+		team := p.Team
+		if team == "" {
+			team = "-"
+		}
+
+		status := "logged out"
+		if p.LoggedIn {
+			status = "logged in"
+		}
+
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\n", marker, p.Name, p.Hostname, team, color.Faint(status))
+	}
+
+	return w.Flush()
+}
+
+func useProfileCmd(cliCtx *cli.Context) error {
+	if err := exactArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+
+	name := cliCtx.Args().First()
+	if err := config.SetActiveProfile(name); err != nil {
+		return cli.NewExitError("Could not use profile: "+err.Error(), -1)
+	}
+
+	fmt.Printf("You are now using the %s profile.\n", name)
+	return nil
+}
+
+func addProfileCmd(cliCtx *cli.Context) error {
+	if err := exactArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+
+	name := cliCtx.Args().First()
+	if err := config.AddProfile(name, cliCtx.String("hostname"), cliCtx.String("scheme")); err != nil {
+		return cli.NewExitError("Could not add profile: "+err.Error(), -1)
+	}
+
+	fmt.Printf("The %s profile has been added.\n\n", name)
+	fmt.Printf("Use `manifold --profile %s login` to log into it.\n", name)
+	return nil
+}
+
+func removeProfileCmd(cliCtx *cli.Context) error {
+	if err := exactArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+
+	name := cliCtx.Args().First()
+	if err := config.RemoveProfile(name); err != nil {
+		return cli.NewExitError("Could not remove profile: "+err.Error(), -1)
+	}
+
+	fmt.Printf("The %s profile has been removed.\n", name)
+	return nil
+}
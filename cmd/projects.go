@@ -25,6 +25,7 @@ import (
 	"github.com/manifoldco/manifold-cli/generated/provisioning/client/operation"
 	pModels "github.com/manifoldco/manifold-cli/generated/provisioning/models"
 	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/output"
 	"github.com/manifoldco/manifold-cli/prompts"
 )
 
@@ -48,7 +49,7 @@ func init() {
 				Flags: append(teamFlags, cli.BoolFlag{
 					Name:  "all",
 					Usage: "List all your projects and teams projects",
-				}),
+				}, outputFlag()),
 				Action: middleware.Chain(middleware.EnsureSession,
 					middleware.LoadTeamPrefs, listProjectsCmd),
 			},
@@ -178,6 +179,20 @@ func listProjectsCmd(cliCtx *cli.Context) error {
 		return cli.NewExitError(fmt.Sprintf("Failed to fetch list of projects: %s", err), -1)
 	}
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		records := make([]output.Project, len(projects))
+		for i, p := range projects {
+			records[i] = output.Project{
+				ID:          p.ID.String(),
+				Name:        string(p.Body.Label),
+				Title:       string(p.Body.Name),
+				Description: p.Body.Description,
+			}
+		}
+
+		return writeRecords(format, records)
+	}
+
 	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
 
 	fmt.Fprintf(w, "%s\n\n", color.Bold("Project"))
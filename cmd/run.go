@@ -8,12 +8,14 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/urfave/cli"
 
 	"github.com/manifoldco/go-manifold"
 	"github.com/manifoldco/manifold-cli/api"
 	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/credcache"
 	"github.com/manifoldco/manifold-cli/errs"
 	"github.com/manifoldco/manifold-cli/generated/marketplace/models"
 	"github.com/manifoldco/manifold-cli/middleware"
@@ -25,10 +27,34 @@ func init() {
 		Name:     "run",
 		Usage:    "Run a process and inject secrets into its environment",
 		Category: "CONFIGURATION",
-		Action: middleware.Chain(middleware.EnsureSession, middleware.LoadDirPrefs,
-			middleware.LoadTeamPrefs, run),
+		Action: middleware.Chain(unlessOffline(middleware.EnsureSession), middleware.LoadDirPrefs,
+			unlessOffline(middleware.LoadTeamPrefs), run),
 		Flags: append(teamFlags, []cli.Flag{
 			projectFlag(),
+			offlineFlag(),
+			cli.BoolFlag{
+				Name:  "watch",
+				Usage: "Poll for credential changes and restart or reload the process",
+			},
+			cli.DurationFlag{
+				Name:  "interval",
+				Usage: "How often credentials are polled in watch mode",
+				Value: time.Minute,
+			},
+			cli.StringFlag{
+				Name:  "reload-signal",
+				Usage: "Send this signal instead of restarting the process when credentials change",
+			},
+			cli.StringFlag{
+				Name:  "restart-signal",
+				Usage: "Signal used to stop the process before restarting it",
+				Value: "TERM",
+			},
+			cli.DurationFlag{
+				Name:  "grace-period",
+				Usage: "How long to wait for the process to stop before killing it",
+				Value: 10 * time.Second,
+			},
 		}...),
 	}
 
@@ -55,57 +81,63 @@ func run(cliCtx *cli.Context) error {
 		return err
 	}
 
-	client, err := api.New(api.Analytics, api.Marketplace)
-	if err != nil {
-		return err
-	}
-
-	cMap := make(map[manifold.ID][]*models.Credential)
+	var opts *watchOptions
+	if cliCtx.Bool("watch") {
+		if cliCtx.Bool("offline") {
+			return errs.NewUsageExitError(cliCtx, fmt.Errorf("--watch cannot be used with --offline"))
+		}
 
-	if projectName != "" {
-		p, err := clients.FetchProjectByLabel(ctx, client.Marketplace, teamID, projectName)
+		opts, err = loadWatchOptions(cliCtx)
 		if err != nil {
-			return cli.NewExitError(fmt.Sprintf("Could not retrieve project: %s", err), -1)
+			return errs.NewUsageExitError(cliCtx, err)
 		}
+	}
 
-		cMap, err = fetchProjectCredentials(ctx, client.Marketplace, p, true)
+	var credentials map[string]string
+	if cliCtx.Bool("offline") {
+		e, err := cachedCredentials(cliCtx, projectName)
 		if err != nil {
-			return cli.NewExitError(fmt.Sprintf("Could not retrieve credentials: %s", err), -1)
+			return err
 		}
+
+		credentials = credcache.Flatten(e.Resources)
 	} else {
-		rs, err := clients.FetchResources(ctx, client.Marketplace, teamID, projectName)
+		client, err := api.New(api.Analytics, api.Marketplace)
 		if err != nil {
-			return cli.NewExitError("Could not retrieve resources: "+err.Error(), -1)
+			return err
 		}
 
-		cMap, err = fetchResourceCredentials(ctx, client.Marketplace, rs, true)
+		resources, err := fetchRunCredentials(ctx, client, teamID, projectName)
 		if err != nil {
-			return cli.NewExitError("Could not retrieve credentials: "+err.Error(), -1)
+			return err
 		}
-	}
 
-	credentials, err := flattenCMap(cMap)
-	if err != nil {
-		return cli.NewExitError("Could not flatten credential map: "+err.Error(), -1)
-	}
+		storeCredentials(cliCtx, projectName, resources)
+		credentials = credcache.Flatten(resources)
 
-	params := map[string]string{}
-	if projectName != "" {
-		params["project"] = projectName
-	}
+		params := map[string]string{}
+		if projectName != "" {
+			params["project"] = projectName
+		}
 
-	client.Analytics.Track(ctx, "Project Run", &params)
+		client.Analytics.Track(ctx, "Project Run", &params)
 
-	cmd := exec.Command(args[0], args[1:]...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Env = filterEnv()
+		if opts != nil {
+			fetch := func() (map[string]string, error) {
+				resources, err := fetchRunCredentials(ctx, client, teamID, projectName)
+				if err != nil {
+					return nil, err
+				}
 
-	for name, value := range credentials {
-		cmd.Env = append(cmd.Env, name+"="+value)
+				storeCredentials(cliCtx, projectName, resources)
+				return credcache.Flatten(resources), nil
+			}
+			return exitStatus(watchCommand(args, credentials, fetch, opts))
+		}
 	}
 
+	cmd := newRunCommand(args, credentials)
+
 	err = cmd.Start()
 	if err != nil {
 		return cli.NewExitError("Could not execute command: "+err.Error(), -1)
@@ -127,18 +159,70 @@ func run(cliCtx *cli.Context) error {
 
 	err = cmd.Wait()
 	close(done)
+	return exitStatus(err)
+}
+
+// fetchRunCredentials returns the credentials of the project's resources, or
+// of every resource of the team when no project is given.
+func fetchRunCredentials(ctx context.Context, client *api.API, teamID *manifold.ID,
+	projectName string) ([]credcache.Resource, error) {
+	cMap := make(map[manifold.ID][]*models.Credential)
+
+	rs, err := clients.FetchResources(ctx, client.Marketplace, teamID, projectName)
 	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			if status, ok := exiterr.Sys().(syscall.WaitStatus); ok {
-				os.Exit(status.ExitStatus())
-				return nil
-			}
+		return nil, cli.NewExitError("Could not retrieve resources: "+err.Error(), -1)
+	}
 
-			return err
+	if projectName != "" {
+		p, err := clients.FetchProjectByLabel(ctx, client.Marketplace, teamID, projectName)
+		if err != nil {
+			return nil, cli.NewExitError(fmt.Sprintf("Could not retrieve project: %s", err), -1)
+		}
+
+		cMap, err = fetchProjectCredentials(ctx, client.Marketplace, p, true)
+		if err != nil {
+			return nil, cli.NewExitError(fmt.Sprintf("Could not retrieve credentials: %s", err), -1)
+		}
+	} else {
+		cMap, err = fetchResourceCredentials(ctx, client.Marketplace, rs, true)
+		if err != nil {
+			return nil, cli.NewExitError("Could not retrieve credentials: "+err.Error(), -1)
+		}
+	}
+
+	return cacheResources(rs, cMap), nil
+}
+
+// newRunCommand prepares the process to execute with the credentials injected
+// into its environment.
+func newRunCommand(args []string, credentials map[string]string) *exec.Cmd {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = filterEnv()
+
+	for name, value := range credentials {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+
+	return cmd
+}
+
+// exitStatus exits with the same status as the child process, if it failed.
+func exitStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if exiterr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exiterr.Sys().(syscall.WaitStatus); ok {
+			os.Exit(status.ExitStatus())
+			return nil
 		}
 	}
 
-	return nil
+	return err
 }
 
 func filterEnv() []string {
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/juju/ansiterm"
+	"github.com/manifoldco/go-manifold"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/credcache"
+	"github.com/manifoldco/manifold-cli/errs"
+	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/output"
+	"github.com/manifoldco/manifold-cli/prompts"
+	"github.com/manifoldco/manifold-cli/scan"
+)
+
+// scanLeakedCode is the exit code of scans finding credentials, telling them
+// apart from scans which failed.
+const scanLeakedCode = 1
+
+func init() {
+	scanCmd := cli.Command{
+		Name:      "scan",
+		Usage:     "Search files and git history for the values of your credentials",
+		Category:  "CONFIGURATION",
+		ArgsUsage: "[path]",
+		Description: "Reports the file, line and credential of every value found, never the value\n" +
+			"   itself. Exits with 1 when credentials are found, so it can be used as a\n" +
+			"   pre-commit hook:\n\n" +
+			"   manifold scan --staged --offline",
+		Action: middleware.Chain(middleware.LoadDirPrefs, unlessOffline(middleware.EnsureSession),
+			unlessOffline(middleware.LoadTeamPrefs), scanCmd),
+		Flags: append(teamFlags, []cli.Flag{
+			projectFlag(),
+			cli.BoolFlag{
+				Name:  "history",
+				Usage: "Also search the lines added by every commit of the repository",
+			},
+			cli.BoolFlag{
+				Name:  "staged",
+				Usage: "Only search the lines staged for the next commit",
+			},
+			offlineFlag(),
+			outputFlag(),
+		}...),
+	}
+
+	cmds = append(cmds, scanCmd)
+}
+
+func scanCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+
+	root := cliCtx.Args().First()
+	if root == "" {
+		root = "."
+	}
+
+	if cliCtx.Bool("history") && cliCtx.Bool("staged") {
+		return errs.NewUsageExitError(cliCtx,
+			cli.NewExitError("--history and --staged cannot be used together", -1))
+	}
+
+	projectName, err := validateName(cliCtx, "project")
+	if err != nil {
+		return err
+	}
+
+	var resources []credcache.Resource
+	if cliCtx.Bool("offline") {
+		e, err := cachedCredentials(cliCtx, projectName)
+		if err != nil {
+			return err
+		}
+
+		resources = e.Resources
+	} else {
+		teamID, err := validateTeamID(cliCtx)
+		if err != nil {
+			return err
+		}
+
+		resources, err = fetchScanCredentials(ctx, teamID, projectName)
+		if err != nil {
+			return err
+		}
+	}
+
+	s := scan.New()
+	for _, r := range resources {
+		for key, value := range r.Values {
+			s.Add(scan.Credential{Resource: r.Name, Key: key}, value)
+		}
+	}
+	if s.Empty() {
+		return cli.NewExitError("No credentials to search for", -1)
+	}
+
+	prompts.SpinStart("Scanning")
+	var findings []scan.Finding
+	var skipped []scan.Skipped
+	switch {
+	case cliCtx.Bool("staged"):
+		findings, err = s.Staged(root)
+	case cliCtx.Bool("history"):
+		findings, skipped, err = s.Tree(root)
+		if err == nil {
+			var history []scan.Finding
+			history, err = s.History(root)
+			findings = append(findings, history...)
+		}
+	default:
+		findings, skipped, err = s.Tree(root)
+	}
+	prompts.SpinStop()
+	if err != nil {
+		return cli.NewExitError("Could not scan "+root+": "+err.Error(), -1)
+	}
+
+	for _, f := range skipped {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %s: %s\n", f.Path, f.Reason)
+	}
+
+	records := make([]output.Leak, 0, len(findings))
+	for _, f := range findings {
+		records = append(records, output.Leak{
+			Path:     f.Path,
+			Line:     f.Line,
+			Commit:   f.Commit,
+			Resource: f.Resource,
+			Key:      f.Key,
+		})
+	}
+
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		if err := writeRecords(format, records); err != nil {
+			return err
+		}
+	} else if err := writeLeaks(records); err != nil {
+		return err
+	}
+
+	if len(records) > 0 {
+		return cli.NewExitError("", scanLeakedCode)
+	}
+
+	return nil
+}
+
+// fetchScanCredentials returns the credentials of every resource of the team,
+// or of the project's resources when a project is given.
+func fetchScanCredentials(ctx context.Context, teamID *manifold.ID,
+	projectName string) ([]credcache.Resource, error) {
+	client, err := api.New(api.Marketplace)
+	if err != nil {
+		return nil, err
+	}
+
+	prompts.SpinStart("Fetching Credentials")
+	defer prompts.SpinStop()
+
+	resources, err := clients.FetchResources(ctx, client.Marketplace, teamID, projectName)
+	if err != nil {
+		return nil, cli.NewExitError("Could not retrieve resources: "+err.Error(), -1)
+	}
+
+	cMap, err := fetchResourceCredentials(ctx, client.Marketplace, resources, true)
+	if err != nil {
+		return nil, cli.NewExitError("Could not retrieve credentials: "+err.Error(), -1)
+	}
+
+	return cacheResources(resources, cMap), nil
+}
+
+func writeLeaks(leaks []output.Leak) error {
+	if len(leaks) == 0 {
+		fmt.Println("No credentials found.")
+		return nil
+	}
+
+	fmt.Printf("%s found in %d places\n\n", color.Bold("Credentials"), len(leaks))
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	w.SetForeground(ansiterm.Gray)
+	fmt.Fprintln(w, "Location\tCommit\tResource\tKey")
+	w.Reset()
+
+	for _, l := range leaks {
+		commit := "-"
+		if l.Commit != "" {
+			commit = shortCommit(l.Commit)
+		}
+
+		fmt.Fprintf(w, "%s:%d\t%s\t%s\t%s\n", l.Path, l.Line, commit, l.Resource,
+			color.Color(ansiterm.Red, l.Key))
+	}
+
+	return w.Flush()
+}
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
 
 	"github.com/juju/ansiterm"
 	"github.com/manifoldco/manifold-cli/api"
 	"github.com/manifoldco/manifold-cli/color"
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/data/catalog"
 	"github.com/manifoldco/manifold-cli/errs"
 	"github.com/manifoldco/manifold-cli/generated/catalog/models"
+	"github.com/manifoldco/manifold-cli/output"
 	"github.com/manifoldco/manifold-cli/prompts"
 	money "github.com/rhymond/go-money"
 
@@ -36,22 +41,79 @@ func init() {
 				Name:      "products",
 				Usage:     "List all products. Specify a product to see its details",
 				ArgsUsage: "[product-name]",
-				Flags:     []cli.Flag{providerFlag()},
+				Flags:     []cli.Flag{providerFlag(), outputFlag()},
 				Action:    listProductsCmd,
 			},
 			{
 				Name:      "plans",
 				Usage:     "List all plans for a product. Specify a plan to see its details",
 				ArgsUsage: "[plan-name]",
-				Flags:     []cli.Flag{providerFlag(), productFlag()},
+				Flags:     []cli.Flag{providerFlag(), productFlag(), outputFlag()},
 				Action:    listPlansCmd,
 			},
+			{
+				Name:      "compare",
+				Usage:     "Compare the plans of a product side by side",
+				ArgsUsage: "<product-name> [plan-name...]",
+				Flags:     []cli.Flag{providerFlag(), outputFlag()},
+				Action:    compareCmd,
+			},
+			{
+				Name:   "refresh",
+				Usage:  "Refresh the catalog of products, plans and regions cached on disk",
+				Action: refreshCatalogCmd,
+			},
 		},
 	}
 
 	cmds = append(cmds, appCmd)
 }
 
+func refreshCatalogCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := maxOptionalArgsLength(cliCtx, 0); err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Catalog)
+	if err != nil {
+		return err
+	}
+
+	prompts.SpinStart("Fetching Catalog")
+	cat, err := catalog.Fetch(ctx, client.Catalog)
+	prompts.SpinStop()
+	if err != nil {
+		return cli.NewExitError("Could not refresh catalog: "+err.Error(), -1)
+	}
+
+	fmt.Printf("Refreshed %d products, %d plans and %d regions.\n", len(cat.Products()),
+		len(cat.Plans()), len(cat.Regions()))
+	return nil
+}
+
+// refreshCatalogDetached refreshes the stale catalog served to a command
+// through `services refresh`, detached so it outlives the command.
+func refreshCatalogDetached() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+
+	proc := exec.Command(exe, "--profile", cfg.Profile, "services", "refresh")
+	detach(proc)
+	if err := proc.Start(); err != nil {
+		return
+	}
+	proc.Process.Release()
+}
+
 func listCategoriesCmd(cliCtx *cli.Context) error {
 	client, err := api.New(api.Analytics, api.Catalog)
 	if err != nil {
@@ -260,6 +322,15 @@ func listProductsCmd(cliCtx *cli.Context) error {
 
 	client.Analytics.Track(client.Context(), "Viewed Services", &params)
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		records := make([]output.Product, len(products))
+		for i, p := range products {
+			records[i] = productRecord(p, providers)
+		}
+
+		return writeRecords(format, records)
+	}
+
 	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
 
 	fmt.Fprintf(w, "%d products from %d providers\n", len(products), len(providers))
@@ -314,6 +385,10 @@ func viewProduct(cliCtx *cli.Context, productName string) error {
 
 	client.Analytics.Track(client.Context(), "Viewed Services", &params)
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		return writeRecords(format, productRecord(product, []*models.Provider{provider}))
+	}
+
 	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
 
 	fmt.Fprintf(w, "Use `manifold services plans --product [product-name] --provider [provider-name]` to view product plans\n\n")
@@ -443,6 +518,15 @@ func listPlansCmd(cliCtx *cli.Context) error {
 
 	client.Analytics.Track(client.Context(), "Viewed Services", &params)
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		records := make([]output.Plan, len(plans))
+		for i, p := range plans {
+			records[i] = planRecord(p, product, provider, nil)
+		}
+
+		return writeRecords(format, records)
+	}
+
 	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
 	fmt.Fprintf(w, "Use `manifold services plans [plan-name] --product [product-name] --provider [provider-name]` to view plan details\n\n")
 
@@ -507,6 +591,10 @@ func viewPlan(cliCtx *cli.Context, planName string) error {
 	}
 	client.Analytics.Track(client.Context(), "Viewed Services", &params)
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		return writeRecords(format, planRecord(plan, product, provider, regions))
+	}
+
 	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
 
 	fmt.Fprintln(w, "Plan\tProduct\tProvider")
@@ -537,3 +625,61 @@ func viewPlan(cliCtx *cli.Context, planName string) error {
 
 	return w.Flush()
 }
+
+// productRecord returns the machine readable representation of a product,
+// looking up its provider label in the given list.
+func productRecord(p *models.Product, providers []*models.Provider) output.Product {
+	record := output.Product{
+		ID:      p.ID.String(),
+		Name:    string(p.Body.Label),
+		Title:   string(p.Body.Name),
+		Tagline: p.Body.Tagline,
+	}
+
+	for _, pp := range providers {
+		if pp.ID == p.Body.ProviderID {
+			record.Provider = string(pp.Body.Label)
+		}
+	}
+
+	return record
+}
+
+// planRecord returns the machine readable representation of a plan. Features
+// and regions are only included when the list of regions is provided.
+func planRecord(p *models.Plan, product *models.Product, provider *models.Provider,
+	regions []*models.Region) output.Plan {
+	record := output.Plan{
+		ID:    p.ID.String(),
+		Name:  string(p.Body.Label),
+		Title: string(p.Body.Name),
+		Cost:  *p.Body.Cost,
+	}
+
+	if product != nil {
+		record.Product = string(product.Body.Label)
+	}
+
+	if provider != nil {
+		record.Provider = string(provider.Body.Label)
+	}
+
+	if regions == nil {
+		return record
+	}
+
+	record.Features = make(map[string]string)
+	for _, f := range p.Body.Features {
+		record.Features[string(f.Feature)] = *f.Value
+	}
+
+	for _, r := range regions {
+		for _, pr := range p.Body.Regions {
+			if r.ID == pr {
+				record.Regions = append(record.Regions, string(r.Body.Name))
+			}
+		}
+	}
+
+	return record
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/manifoldco/manifold-cli/generated/identity/models"
 	iModels "github.com/manifoldco/manifold-cli/generated/identity/models"
 	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/output"
 	"github.com/manifoldco/manifold-cli/prompts"
 )
 
@@ -62,13 +63,14 @@ func init() {
 			{
 				Name:  "members",
 				Usage: "List members of a team",
-				Flags: teamFlags,
+				Flags: append(teamFlags, outputFlag()),
 				Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
 					middleware.LoadTeamPrefs, membersTeamCmd),
 			},
 			{
 				Name:   "list",
 				Usage:  "List all your teams",
+				Flags:  []cli.Flag{outputFlag()},
 				Action: middleware.Chain(middleware.EnsureSession, listTeamCmd),
 			},
 			{
@@ -253,6 +255,32 @@ func membersTeamCmd(cliCtx *cli.Context) error {
 		return cli.NewExitError(fmt.Sprintf("Failed to fetch list of invites: %s", err), -1)
 	}
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		records := make([]output.Member, 0, len(members)+len(invites))
+		for _, m := range members {
+			records = append(records, output.Member{
+				Name:   string(m.Name),
+				Email:  string(m.Email),
+				Role:   string(m.Role),
+				Status: "active",
+			})
+		}
+		for _, i := range invites {
+			role := string(i.Body.Role)
+			if role == "" {
+				role = "admin"
+			}
+			records = append(records, output.Member{
+				Name:   string(i.Body.Name),
+				Email:  string(i.Body.Email),
+				Role:   role,
+				Status: "pending",
+			})
+		}
+
+		return writeRecords(format, records)
+	}
+
 	fmt.Printf("%d members and %d invites\n", len(members), len(invites))
 	fmt.Println("Use `manifold switch` to change to a different team")
 	fmt.Println()
@@ -294,16 +322,29 @@ func listTeamCmd(cliCtx *cli.Context) error {
 		return cli.NewExitError(fmt.Sprintf("Failed to fetch list of teams: %s", err), -1)
 	}
 
-	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
-
-	fmt.Fprintf(w, "%s\t%s\n", color.Bold("Name"), color.Bold("Members"))
-
 	sort.Slice(teams, func(i int, j int) bool {
 		a := strings.ToLower(teams[i].Name)
 		b := strings.ToLower(teams[j].Name)
 		return b > a
 	})
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		records := make([]output.Team, len(teams))
+		for i, t := range teams {
+			records[i] = output.Team{
+				Name:    t.Name,
+				Title:   t.Title,
+				Members: t.Members,
+			}
+		}
+
+		return writeRecords(format, records)
+	}
+
+	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
+
+	fmt.Fprintf(w, "%s\t%s\n", color.Bold("Name"), color.Bold("Members"))
+
 	for _, team := range teams {
 		fmt.Fprintf(w, "%s (%s)\t%d\n", team.Name, color.Faint(team.Title), team.Members)
 	}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/urfave/cli"
+
+	"github.com/manifoldco/manifold-cli/api"
+	"github.com/manifoldco/manifold-cli/clients"
+	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/prompts"
+	"github.com/manifoldco/manifold-cli/render"
+
+	"github.com/manifoldco/manifold-cli/generated/marketplace/models"
+)
+
+func init() {
+	templateCmd := cli.Command{
+		Name:     "template",
+		Usage:    "Render credentials into configuration files",
+		Category: "CONFIGURATION",
+		Subcommands: []cli.Command{
+			{
+				Name:      "render",
+				Usage:     "Render a Go template using the credentials of your resources",
+				ArgsUsage: "[template]",
+				Flags: append(teamFlags, []cli.Flag{
+					projectFlag(),
+					cli.StringFlag{
+						Name:  "out, o",
+						Usage: "Write the rendered template to this file instead of stdout",
+					},
+				}...),
+				Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
+					middleware.LoadTeamPrefs, renderTemplateCmd),
+			},
+		},
+	}
+
+	cmds = append(cmds, templateCmd)
+}
+
+func renderTemplateCmd(cliCtx *cli.Context) error {
+	ctx := context.Background()
+
+	if err := exactArgsLength(cliCtx, 1); err != nil {
+		return err
+	}
+
+	in := cliCtx.Args().First()
+	text, err := ioutil.ReadFile(in)
+	if err != nil {
+		return cli.NewExitError("Could not read template: "+err.Error(), -1)
+	}
+
+	projectName, err := validateName(cliCtx, "project")
+	if err != nil {
+		return err
+	}
+
+	teamID, err := validateTeamID(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, err := api.New(api.Analytics, api.Marketplace)
+	if err != nil {
+		return err
+	}
+
+	prompts.SpinStart("Fetching Credentials")
+	data, err := templateData(ctx, client, teamID, projectName)
+	prompts.SpinStop()
+	if err != nil {
+		return err
+	}
+
+	// Render in memory first, so a failing template never truncates the
+	// existing output file
+	buf := &bytes.Buffer{}
+	err = render.Execute(buf, filepath.Base(in), string(text), data)
+	if err != nil {
+		return cli.NewExitError("Could not render template: "+err.Error(), -1)
+	}
+
+	params := map[string]string{
+		"format": "template",
+	}
+	if projectName != "" {
+		params["project"] = projectName
+	}
+	client.Analytics.Track(ctx, "Fetch Credentials", &params)
+
+	out := cliCtx.String("out")
+	if out == "" {
+		_, err = buf.WriteTo(os.Stdout)
+		return err
+	}
+
+	// The rendered file contains secrets, keep it private like ~/.manifoldrc
+	err = ioutil.WriteFile(out, buf.Bytes(), 0600)
+	if err == nil {
+		err = os.Chmod(out, 0600)
+	}
+	if err != nil {
+		return cli.NewExitError("Could not write rendered template: "+err.Error(), -1)
+	}
+
+	return nil
+}
+
+// templateData fetches the credentials and resource metadata exposed to
+// templates. Without a project, only resources outside of projects are used,
+// matching `manifold export`.
+func templateData(ctx context.Context, client *api.API, teamID *manifold.ID,
+	projectName string) (*render.Data, error) {
+	resources, err := clients.FetchResources(ctx, client.Marketplace, teamID, projectName)
+	if err != nil {
+		return nil, cli.NewExitError("Could not retrieve resources: "+err.Error(), -1)
+	}
+
+	data := &render.Data{
+		Resources: make(map[string]render.Resource),
+	}
+
+	var cMap map[manifold.ID][]*models.Credential
+	if projectName != "" {
+		p, err := clients.FetchProjectByLabel(ctx, client.Marketplace, teamID, projectName)
+		if err != nil {
+			return nil, cli.NewExitError(fmt.Sprintf("Could not retrieve project: %s", err), -1)
+		}
+
+		data.Project = &render.Project{
+			ID:    p.ID.String(),
+			Name:  string(p.Body.Label),
+			Title: string(p.Body.Name),
+		}
+
+		cMap, err = fetchProjectCredentials(ctx, client.Marketplace, p, true)
+		if err != nil {
+			return nil, cli.NewExitError(fmt.Sprintf("Could not retrieve credentials: %s", err), -1)
+		}
+	} else {
+		resources = filterResourcesWithoutProjects(resources)
+		cMap, err = fetchResourceCredentials(ctx, client.Marketplace, resources, true)
+		if err != nil {
+			return nil, cli.NewExitError("Could not retrieve credentials: "+err.Error(), -1)
+		}
+	}
+
+	data.Credentials, err = flattenCMap(cMap)
+	if err != nil {
+		return nil, cli.NewExitError("Could not flatten credential map: "+err.Error(), -1)
+	}
+
+	rMap := indexResources(resources)
+	for rID, credentials := range cMap {
+		r, ok := rMap[rID]
+		if !ok {
+			continue
+		}
+
+		values := make(map[string]string)
+		for _, c := range credentials {
+			for name, value := range c.Body.Values {
+				values[name] = value
+			}
+		}
+
+		data.Resources[string(r.Body.Label)] = render.Resource{
+			ID:          r.ID.String(),
+			Name:        string(r.Body.Label),
+			Title:       string(r.Body.Name),
+			Credentials: values,
+		}
+	}
+
+	return data, nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/manifoldco/manifold-cli/generated/identity/client/authentication"
 	"github.com/manifoldco/manifold-cli/generated/identity/models"
 	"github.com/manifoldco/manifold-cli/middleware"
+	"github.com/manifoldco/manifold-cli/output"
 	"github.com/manifoldco/manifold-cli/prompts"
 )
 
@@ -27,14 +28,14 @@ func init() {
 			{
 				Name:  "create",
 				Usage: "Create a new token",
-				Flags: teamFlags,
+				Flags: append(teamFlags, descriptionFlag(), roleFlag()),
 				Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
 					middleware.LoadTeamPrefs, createTokenCmd),
 			},
 			{
 				Name:  "list",
 				Usage: "List existing tokens",
-				Flags: teamFlags,
+				Flags: append(teamFlags, outputFlag()),
 				Action: middleware.Chain(middleware.LoadDirPrefs, middleware.EnsureSession,
 					middleware.LoadTeamPrefs, listTokensCmd),
 			},
@@ -68,19 +69,25 @@ func createTokenCmd(cliCtx *cli.Context) error {
 		return err
 	}
 
-	desc, err := prompts.TokenDescription()
-	if err != nil {
-		return prompts.HandleSelectError(err, "Failed to describe token")
+	desc := cliCtx.String("description")
+	if desc == "" {
+		desc, err = prompts.TokenDescription()
+		if err != nil {
+			return prompts.HandleSelectError(err, "Failed to describe token")
+		}
 	}
 
-	roles, err := rolesString(tID, client)
-	if err != nil {
-		return err
-	}
+	role := cliCtx.String("role")
+	if role == "" {
+		roles, err := rolesString(tID, client)
+		if err != nil {
+			return err
+		}
 
-	role, err := prompts.SelectRole(roles)
-	if err != nil {
-		return prompts.HandleSelectError(err, "Failed to select role")
+		role, err = prompts.SelectRole(roles)
+		if err != nil {
+			return prompts.HandleSelectError(err, "Failed to select role")
+		}
 	}
 
 	var teamID, userID *manifold.ID
@@ -136,7 +143,7 @@ func deleteTokenCmd(cliCtx *cli.Context) error {
 	}
 
 	if !cliCtx.Bool("yes") {
-		_, err = prompts.Confirm("Are you sure you want to revoke this token? It cannot be undone")
+		_, err = prompts.Confirm("Are you sure you want to revoke this token? It cannot be undone", "--yes")
 		if err != nil {
 			return err
 		}
@@ -164,6 +171,20 @@ func listTokensCmd(cliCtx *cli.Context) error {
 		return err
 	}
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		records := make([]output.Token, len(tokens))
+		for i, t := range tokens {
+			records[i] = output.Token{
+				ID:          t.ID.String(),
+				Token:       fmt.Sprintf("%s****%s", *t.Body.FirstFour, *t.Body.LastFour),
+				Description: *t.Body.Description,
+				Role:        string(t.Body.Role),
+			}
+		}
+
+		return writeRecords(format, records)
+	}
+
 	fmt.Printf("%d tokens found\n", len(tokens))
 	if len(tokens) == 0 {
 		fmt.Println("Use `manifold tokens create` to issue a token")
@@ -29,6 +29,7 @@ func init() {
 			middleware.LoadTeamPrefs, view),
 		Flags: append(teamFlags, []cli.Flag{
 			projectFlag(),
+			outputFlag(),
 		}...),
 	}
 
@@ -163,6 +164,19 @@ func view(cliCtx *cli.Context) error {
 		projectName = string(project.Body.Label)
 	}
 
+	if format := outputFormat(cliCtx); format.IsMachine() {
+		record, err := resourceRecord(ctx, catalog, resource, statuses)
+		if err != nil {
+			return err
+		}
+
+		if projectID != nil {
+			record.Project = projectName
+		}
+
+		return writeRecords(format, record)
+	}
+
 	fmt.Println("Use `manifold update [resource-name] --project [project]` to edit your resource")
 	fmt.Println("")
 	w := ansiterm.NewTabWriter(os.Stdout, 0, 0, 8, ' ', 0)
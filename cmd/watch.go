@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+// signals lists the signals which can be used with --reload-signal and
+// --restart-signal. Platform specific signals are added in signals_unix.go.
+var signals = map[string]os.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"TERM": syscall.SIGTERM,
+	"KILL": syscall.SIGKILL,
+}
+
+// watchOptions configures how `run --watch` reacts to credential changes.
+type watchOptions struct {
+	interval      time.Duration
+	grace         time.Duration
+	restartSignal os.Signal
+
+	// reloadSignal is sent to the process instead of restarting it, when set.
+	reloadSignal os.Signal
+}
+
+func loadWatchOptions(cliCtx *cli.Context) (*watchOptions, error) {
+	opts := &watchOptions{
+		interval: cliCtx.Duration("interval"),
+		grace:    cliCtx.Duration("grace-period"),
+	}
+
+	if opts.interval <= 0 {
+		return nil, fmt.Errorf("--interval must be greater than zero")
+	}
+
+	var err error
+	opts.restartSignal, err = parseSignal(cliCtx.String("restart-signal"))
+	if err != nil {
+		return nil, err
+	}
+
+	if name := cliCtx.String("reload-signal"); name != "" {
+		opts.reloadSignal, err = parseSignal(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return opts, nil
+}
+
+// parseSignal returns the signal matching the name, with or without the SIG
+// prefix.
+func parseSignal(name string) (os.Signal, error) {
+	key := strings.TrimPrefix(strings.ToUpper(name), "SIG")
+	s, ok := signals[key]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported signal %q", name)
+	}
+
+	return s, nil
+}
+
+// watchCommand runs the command, polling for credentials on every interval.
+// When they change, the process is either sent the reload signal or stopped
+// and started again with the new environment. Every signal received is
+// forwarded to the process, which decides how to handle it; watching only
+// stops once the process exits.
+func watchCommand(args []string, credentials map[string]string,
+	fetch func() (map[string]string, error), opts *watchOptions) error {
+
+	cmd, exited, err := startWatched(args, credentials)
+	if err != nil {
+		return err
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs)
+	defer signal.Stop(sigs)
+
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case s := <-sigs:
+			cmd.Process.Signal(s)
+		case err := <-exited:
+			return err
+		case <-ticker.C:
+			next, err := fetch()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Could not refresh credentials: %s\n", err)
+				continue
+			}
+
+			if reflect.DeepEqual(next, credentials) {
+				continue
+			}
+			credentials = next
+
+			if opts.reloadSignal != nil {
+				fmt.Fprintln(os.Stderr, "Credentials changed, reloading process")
+				cmd.Process.Signal(opts.reloadSignal)
+				continue
+			}
+
+			fmt.Fprintln(os.Stderr, "Credentials changed, restarting process")
+			stopWatched(cmd, exited, opts)
+
+			cmd, exited, err = startWatched(args, credentials)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func startWatched(args []string, credentials map[string]string) (*exec.Cmd, chan error, error) {
+	cmd := newRunCommand(args, credentials)
+	if err := cmd.Start(); err != nil {
+		return nil, nil, cli.NewExitError("Could not execute command: "+err.Error(), -1)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	return cmd, exited, nil
+}
+
+// stopWatched sends the restart signal and waits for the grace period before
+// killing the process.
+func stopWatched(cmd *exec.Cmd, exited chan error, opts *watchOptions) {
+	cmd.Process.Signal(opts.restartSignal)
+
+	select {
+	case <-exited:
+	case <-time.After(opts.grace):
+		fmt.Fprintln(os.Stderr, "Process did not stop in time, killing it")
+		cmd.Process.Kill()
+		<-exited
+	}
+}
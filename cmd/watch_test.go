@@ -0,0 +1,147 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// watchScript runs a shell script writing to a log, returning the log path
+// and a function reading it.
+func watchScript(t *testing.T) (string, func() string, func()) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out")
+	read := func() string {
+		b, _ := ioutil.ReadFile(out)
+		return string(b)
+	}
+
+	return out, read, func() { os.RemoveAll(dir) }
+}
+
+func waitFor(t *testing.T, read func() string, s string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(read(), s) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %q to be logged, got %q", s, read())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// changingCredentials returns TOKEN=a until change is called, and TOKEN=b
+// afterwards.
+func changingCredentials() (func() (map[string]string, error), func()) {
+	var mu sync.Mutex
+	token := "a"
+
+	fetch := func() (map[string]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return map[string]string{"TOKEN": token}, nil
+	}
+	change := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		token = "b"
+	}
+
+	return fetch, change
+}
+
+func TestWatchCommand(t *testing.T) {
+	opts := &watchOptions{
+		interval:      20 * time.Millisecond,
+		grace:         time.Second,
+		restartSignal: syscall.SIGTERM,
+	}
+
+	t.Run("restarts the process with new credentials", func(t *testing.T) {
+		out, read, cleanup := watchScript(t)
+		defer cleanup()
+
+		fetch, change := changingCredentials()
+		change()
+
+		script := `echo "$TOKEN" >> ` + out + `; [ "$TOKEN" = b ] && exit 0; exec sleep 5`
+		err := watchCommand([]string{"sh", "-c", script}, map[string]string{"TOKEN": "a"}, fetch, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if log := read(); log != "a\nb\n" {
+			t.Errorf("Expected the process to be restarted once, got %q", log)
+		}
+	})
+
+	t.Run("sends the reload signal", func(t *testing.T) {
+		out, read, cleanup := watchScript(t)
+		defer cleanup()
+
+		fetch, change := changingCredentials()
+		change()
+
+		reload := *opts
+		reload.reloadSignal = syscall.SIGHUP
+
+		script := `trap 'echo reloaded >> ` + out + `; exit 0' HUP; echo started >> ` + out +
+			`; while true; do sleep 0.05; done`
+		err := watchCommand([]string{"sh", "-c", script}, map[string]string{"TOKEN": "a"}, fetch, &reload)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if log := read(); log != "started\nreloaded\n" {
+			t.Errorf("Expected the process to be reloaded in place, got %q", log)
+		}
+	})
+
+	t.Run("forwards signals and keeps watching", func(t *testing.T) {
+		out, read, cleanup := watchScript(t)
+		defer cleanup()
+
+		fetch, change := changingCredentials()
+
+		reload := *opts
+		reload.reloadSignal = syscall.SIGHUP
+
+		script := `trap 'echo usr1 >> ` + out + `' USR1; trap 'echo reloaded >> ` + out +
+			`; exit 0' HUP; echo started >> ` + out + `; while true; do sleep 0.05; done`
+
+		done := make(chan error, 1)
+		go func() {
+			done <- watchCommand([]string{"sh", "-c", script}, map[string]string{"TOKEN": "a"}, fetch, &reload)
+		}()
+
+		waitFor(t, read, "started")
+		time.Sleep(100 * time.Millisecond)
+		syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+		waitFor(t, read, "usr1")
+
+		change()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the process to be reloaded after a forwarded signal")
+		}
+
+		if log := read(); log != "started\nusr1\nreloaded\n" {
+			t.Errorf("Expected the signal to be forwarded, got %q", log)
+		}
+	})
+}
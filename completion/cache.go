@@ -0,0 +1,96 @@
+package completion
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTTL keeps suggestions fresh enough while typing a command, without
+// calling the API on every tab.
+const DefaultTTL = 2 * time.Minute
+
+type entry struct {
+	At     time.Time `json:"at"`
+	Values []string  `json:"values"`
+}
+
+// Cache stores suggestions in a single file, keyed by the kind of value and
+// the profile and team they belong to.
+type Cache struct {
+	path string
+	ttl  time.Duration
+}
+
+// NewCache returns a Cache stored at path. Entries older than ttl are not
+// served.
+func NewCache(path string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Cache{path: path, ttl: ttl}
+}
+
+// Get returns the values stored under key, unless they expired.
+func (c *Cache) Get(key string) ([]string, bool) {
+	e, ok := c.read()[key]
+	if !ok || time.Since(e.At) > c.ttl {
+		return nil, false
+	}
+
+	return e.Values, true
+}
+
+// Put stores the values under key, dropping expired entries.
+func (c *Cache) Put(key string, values []string) error {
+	entries := c.read()
+	for k, e := range entries {
+		if time.Since(e.At) > c.ttl {
+			delete(entries, k)
+		}
+	}
+	entries[key] = entry{At: time.Now(), Values: values}
+
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(c.path), ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.path)
+}
+
+func (c *Cache) read() map[string]entry {
+	entries := make(map[string]entry)
+
+	b, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return entries
+	}
+
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return make(map[string]entry)
+	}
+
+	return entries
+}
@@ -0,0 +1,72 @@
+// Package completion provides the shell scripts completing manifold commands,
+// along with a short lived cache of the values they suggest.
+//
+// Every script calls manifold with the words typed so far followed by
+// --generate-bash-completion, passing the word being completed through
+// EnvWord.
+package completion
+
+import (
+	"errors"
+	"sort"
+)
+
+// EnvWord holds the word being completed, so flags are only suggested once a
+// dash is typed.
+const EnvWord = "MANIFOLD_COMPLETION_WORD"
+
+// ErrUnknownShell is returned for shells without a completion script.
+var ErrUnknownShell = errors.New("Unknown shell, expected bash, zsh or fish")
+
+var scripts = map[string]string{
+	"bash": `#! /bin/bash
+export MANIFOLD_AUTOCOMPLETE=true
+_manifold_bash_autocomplete() {
+  local cur opts
+  COMPREPLY=()
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  opts=$( MANIFOLD_COMPLETION_WORD="${cur}" "${COMP_WORDS[@]:0:$COMP_CWORD}" --generate-bash-completion 2>/dev/null )
+  COMPREPLY=( $(compgen -W "${opts}" -- "${cur}") )
+  return 0
+}
+complete -F _manifold_bash_autocomplete manifold
+`,
+	"zsh": `#compdef manifold
+export MANIFOLD_AUTOCOMPLETE=true
+_manifold() {
+  local -a opts
+  opts=("${(@f)$( MANIFOLD_COMPLETION_WORD="${words[CURRENT]}" ${words[1,CURRENT-1]} --generate-bash-completion 2>/dev/null )}")
+  compadd -a opts
+}
+compdef _manifold manifold
+`,
+	"fish": `set -gx MANIFOLD_AUTOCOMPLETE true
+function __manifold_complete
+  set -l tokens (commandline -opc)
+  set -l current (commandline -ct)
+  env MANIFOLD_COMPLETION_WORD=$current $tokens --generate-bash-completion 2>/dev/null
+end
+complete -c manifold -f -a '(__manifold_complete)'
+`,
+}
+
+// Shells returns the shells with a completion script.
+func Shells() []string {
+	shells := make([]string, 0, len(scripts))
+	for s := range scripts {
+		shells = append(shells, s)
+	}
+
+	sort.Strings(shells)
+	return shells
+}
+
+// Script returns the completion script of a shell.
+func Script(shell string) (string, error) {
+	s, ok := scripts[shell]
+	if !ok {
+		return "", ErrUnknownShell
+	}
+
+	return s, nil
+}
@@ -0,0 +1,55 @@
+package completion
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScript(t *testing.T) {
+	for _, shell := range Shells() {
+		s, err := Script(shell)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %s", shell, err)
+		}
+		if !strings.Contains(s, "--generate-bash-completion") || !strings.Contains(s, EnvWord) {
+			t.Errorf("Expected the %s script to request completions, got:\n%s", shell, s)
+		}
+	}
+
+	if _, err := Script("tcsh"); err != ErrUnknownShell {
+		t.Errorf("Expected ErrUnknownShell, got %v", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "completion")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cache", "completion.json")
+	c := NewCache(path, time.Hour)
+
+	if _, ok := c.Get("default:projects"); ok {
+		t.Error("Expected an empty cache")
+	}
+
+	if err := c.Put("default:projects", []string{"web", "api"}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	values, ok := c.Get("default:projects")
+	if !ok || strings.Join(values, ",") != "web,api" {
+		t.Errorf("Expected the stored values, got %v", values)
+	}
+
+	expired := NewCache(path, time.Nanosecond)
+	if _, ok := expired.Get("default:projects"); ok {
+		t.Error("Expected the entry to expire")
+	}
+}
@@ -2,16 +2,20 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/user"
 	"path"
 	"path/filepath"
+	"sync"
 
 	"github.com/go-ini/ini"
 	"github.com/manifoldco/go-manifold"
 	"github.com/stripe/stripe-go"
 	"gopkg.in/yaml.v2"
+
+	"github.com/manifoldco/manifold-cli/secrets"
 )
 
 // Version represents the version of the cli. This variable is updated at build
@@ -21,6 +25,12 @@ var Version = "dev"
 // StripePublishableKey facilitates secure transmission of payment values
 var StripePublishableKey = "pk_live_A6qSWh1v4SrNnrWSftgDcKFQ"
 
+// BillingPublicKey is the Manifold key endorsing the keys which sign
+// subscription events, pinned at build time. Releases refuse to build
+// without it. The billing_public_key key of a profile only overrides it for
+// hosts other than Manifold's.
+var BillingPublicKey = ""
+
 // GitHubClientID facilitates logins with GitHub
 var GitHubClientID = "40ff4213256c4b253c7f"
 var GitHubCallback = "https://dashboard.manifold.co/login/oauth/github"
@@ -76,36 +86,109 @@ func LoadIgnoreLegacy() (*Config, error) {
 }
 
 func loadConfiguration() (*Config, error) {
-	rcpath, err := RCPath()
+	f, err := loadFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateProfiles(f); err != nil {
+		return nil, err
+	}
+
+	return mapProfile(f, activeProfile(f))
+}
+
+// warnToken prints the warning about an unreadable token once per command,
+// as the config is loaded many times.
+var warnToken sync.Once
+
+// mapProfile returns the values of a profile, applied over the defaults.
+func mapProfile(f *ini.File, profile string) (*Config, error) {
+	cfg := newConfig(profile)
+
+	sec, err := f.GetSection(profile)
+	if err != nil {
+		if profile != DefaultProfile {
+			return nil, ErrProfileNotFound
+		}
+		return cfg, nil
+	}
+
+	// Profiles written before secret stores existed keep their token in
+	// plaintext until it's migrated
+	cfg.TokenStore = ""
+
+	err = sec.MapTo(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := &Config{
+	// An unreadable secret store leaves the profile logged out, so commands
+	// such as logout and config migrate-token can still fix it
+	if err := cfg.loadToken(); err != nil {
+		warnToken.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: could not read the session token from the %s store, "+
+				"continuing logged out: %s\n", cfg.TokenStore, err)
+		})
+	}
+
+	return cfg, nil
+}
+
+func newConfig(profile string) *Config {
+	return &Config{
+		Profile:         profile,
 		Hostname:        defaultHostname,
 		AuthToken:       "",
 		TransportScheme: defaultScheme,
 		Analytics:       defaultAnalytics,
 		GitHubCallback:  GitHubCallback,
+		TokenStore:      secrets.Auto,
+	}
+}
+
+// loadFile reads ~/.manifoldrc, returning an empty file if it doesn't exist.
+func loadFile() (*ini.File, error) {
+	rcpath, err := RCPath()
+	if err != nil {
+		return nil, err
 	}
 
 	f, err := os.OpenFile(rcpath, os.O_RDONLY, requiredPermissions)
 	switch {
 	case err == nil: // ok
 	case os.IsNotExist(err):
-		return cfg, nil
+		return ini.Empty(), nil
 	default:
 		return nil, err
 	}
 
 	f.Close()
 
-	err = ini.MapTo(cfg, rcpath)
+	return ini.Load(rcpath)
+}
+
+// writeFile writes the ini file to ~/.manifoldrc and sets the appropriate
+// permissions.
+func writeFile(cfg *ini.File) error {
+	rcpath, err := RCPath()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return cfg, nil
+	f, err := os.OpenFile(rcpath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, requiredPermissions)
+	if err != nil {
+		return err
+	}
+
+	// Finish writing file before we close, due to race condition inside go-ini
+	defer func() {
+		f.Sync()
+		f.Close()
+	}()
+
+	_, err = cfg.WriteTo(f)
+	return err
 }
 
 func loadConfigurationCheckLegacy() (*Config, error) {
@@ -134,6 +217,9 @@ func RCPath() (string, error) {
 // Config represents the configuration which is stored inside a ~/.manifoldrc
 // file in ini format.
 type Config struct {
+	// Profile is the name of the profile the values were loaded from
+	Profile string `ini:"-"`
+
 	Hostname        string `ini:"hostname"`
 	AuthToken       string `ini:"auth_token"`
 	TransportScheme string `ini:"scheme"`
@@ -143,6 +229,39 @@ type Config struct {
 	TeamName        string `ini:"team_name,omitempty"`
 	TeamID          string `ini:"team_id,omitempty"`
 	GitHubCallback  string `ini:"github_callback,omitempty"`
+
+	// CredentialCache enables the encrypted offline credential cache
+	CredentialCache    bool   `ini:"credential_cache,omitempty"`
+	CredentialCacheTTL string `ini:"credential_cache_ttl,omitempty"`
+
+	// TokenStore is the secret store holding AuthToken. The token is kept in
+	// this file when it's empty.
+	TokenStore  string `ini:"token_store,omitempty"`
+	storedToken string `ini:"-"`
+
+	// HTTPRetries and HTTPTimeout control how many times failed requests are
+	// retried, and how long every attempt may take, such as 30s
+	HTTPRetries string `ini:"http_retries,omitempty"`
+	HTTPTimeout string `ini:"http_timeout,omitempty"`
+
+	// CatalogCacheTTL is how long the catalog is used from disk before it's
+	// refreshed, 0 disables the catalog cache
+	CatalogCacheTTL string `ini:"catalog_cache_ttl,omitempty"`
+
+	// BillingPublicKey overrides the Manifold key subscription events are
+	// verified against, for hosts other than Manifold's
+	BillingPublicKey string `ini:"billing_public_key,omitempty"`
+}
+
+// BillingKey returns the key subscription events of the profile's host are
+// verified against. The key pinned at build time is always used for
+// Manifold's host, so a profile can't replace the trust anchor.
+func (c *Config) BillingKey() string {
+	if c.Hostname == defaultHostname || c.BillingPublicKey == "" {
+		return BillingPublicKey
+	}
+
+	return c.BillingPublicKey
 }
 
 // IdentifyLegacyValues identifies if a user's config file is out of date
@@ -166,41 +285,75 @@ func (c *Config) IdentifyLegacyValues() error {
 	return nil
 }
 
-// Write writes the contents of the Config struct to ~/.manifoldrc and sets the
-// appropriate permissions.
+// Write writes the contents of the Config struct to its profile inside
+// ~/.manifoldrc and sets the appropriate permissions. Other profiles are left
+// untouched.
 func (c *Config) Write() error {
-	rcpath, err := RCPath()
+	f, err := loadFile()
 	if err != nil {
 		return err
 	}
 
-	f, err := os.OpenFile(rcpath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, requiredPermissions)
+	profile := c.Profile
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	stored := *c
+	if c.TokenStore != "" {
+		if err := c.storeToken(profile); err != nil {
+			return err
+		}
+
+		stored.TokenStore = c.TokenStore
+		stored.AuthToken = ""
+	}
+
+	f.DeleteSection(profile)
+	sec, err := f.NewSection(profile)
 	if err != nil {
 		return err
 	}
 
-	// Finish writing file before we close, due to race condition inside go-ini
-	defer func() {
-		f.Sync()
-		f.Close()
-	}()
-
-	cfg := ini.Empty()
-	err = ini.ReflectFrom(cfg, c)
+	err = sec.ReflectFrom(&stored)
 	if err != nil {
 		return err
 	}
 
-	_, err = cfg.WriteTo(f)
-	return err
+	def := f.Section(ini.DEFAULT_SECTION)
+	if !def.HasKey(profileKey) {
+		def.Key(profileKey).SetValue(profile)
+	}
+
+	return writeFile(f)
 }
 
 // ManifoldYaml represents the standard project config object
 type ManifoldYaml struct {
-	Project string                 `yaml:"project,omitempty" flag:"project,omitempty"`
-	Team    string                 `yaml:"team,omitempty" flag:"team,omitempty"`
-	Plugins map[string]interface{} `yaml:"plugins,omitempty"`
-	Path    string                 `yaml:"-" json:"-"`
+	Project   string                 `yaml:"project,omitempty" flag:"project,omitempty"`
+	Team      string                 `yaml:"team,omitempty" flag:"team,omitempty"`
+	Plugins   map[string]interface{} `yaml:"plugins,omitempty"`
+	Resources []ResourceSpec         `yaml:"resources,omitempty"`
+	Path      string                 `yaml:"-" json:"-"`
+}
+
+// ResourceSpec declares a resource managed by `manifold plan` and
+// `manifold apply`. A resource without a product is a custom resource.
+type ResourceSpec struct {
+	Name    string `yaml:"name"`
+	Title   string `yaml:"title,omitempty"`
+	Product string `yaml:"product,omitempty"`
+	Plan    string `yaml:"plan,omitempty"`
+	Region  string `yaml:"region,omitempty"`
+
+	// Project defaults to the project of the ManifoldYaml
+	Project string `yaml:"project,omitempty"`
+
+	// Aliases maps original credential names to their alias
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+
+	// Config holds the config values of a custom resource
+	Config map[string]string `yaml:"config,omitempty"`
 }
 
 // GetPlugin retrieves plugins config for the given plugin name
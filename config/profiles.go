@@ -0,0 +1,227 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"regexp"
+
+	"github.com/go-ini/ini"
+)
+
+const (
+	// DefaultProfile is the profile used when none was selected
+	DefaultProfile = "default"
+
+	// EnvProfile is the environment variable selecting the profile to use
+	EnvProfile = "MANIFOLD_PROFILE"
+
+	profileKey = "profile"
+)
+
+// ErrProfileNotFound is returned when the selected profile doesn't exist
+var ErrProfileNotFound = errors.New("Profile not found, add it with `manifold profile add`")
+
+// ErrProfileExists is returned when adding a profile which already exists
+var ErrProfileExists = errors.New("A profile with this name already exists")
+
+// ErrActiveProfile is returned when removing the active profile
+var ErrActiveProfile = errors.New("Cannot remove the active profile, use another one first")
+
+// ErrInvalidProfile is returned when a profile name isn't valid
+var ErrInvalidProfile = errors.New(
+	"Profile names can only contain lowercase letters, numbers, hyphens and underscores")
+
+var profileNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_\-]*$`)
+
+// profileOverride is the profile selected for the current invocation
+var profileOverride string
+
+// UseProfile selects the profile loaded by Load for the current invocation,
+// without changing the active profile stored in ~/.manifoldrc.
+func UseProfile(name string) {
+	profileOverride = name
+}
+
+// Profiles returns the names of every profile along with the name of the
+// active one.
+func Profiles() ([]string, string, error) {
+	f, err := loadFile()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := migrateProfiles(f); err != nil {
+		return nil, "", err
+	}
+
+	var names []string
+	for _, name := range f.SectionStrings() {
+		if name != ini.DEFAULT_SECTION {
+			names = append(names, name)
+		}
+	}
+
+	return names, activeProfile(f), nil
+}
+
+// LoadProfile returns the values of the named profile, whichever profile is
+// active.
+func LoadProfile(name string) (*Config, error) {
+	f, err := loadFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateProfiles(f); err != nil {
+		return nil, err
+	}
+
+	return mapProfile(f, name)
+}
+
+// AddProfile creates a new logged out profile pointing at hostname over
+// scheme. The defaults are used for empty values.
+func AddProfile(name, hostname, scheme string) error {
+	if !profileNameRegexp.MatchString(name) {
+		return ErrInvalidProfile
+	}
+
+	f, err := loadFile()
+	if err != nil {
+		return err
+	}
+
+	if err := migrateProfiles(f); err != nil {
+		return err
+	}
+
+	if _, err := f.GetSection(name); err == nil {
+		return ErrProfileExists
+	}
+
+	cfg := newConfig(name)
+	if hostname != "" {
+		cfg.Hostname = hostname
+	}
+	if scheme != "" {
+		cfg.TransportScheme = scheme
+	}
+
+	return cfg.Write()
+}
+
+// SetActiveProfile stores the profile used when none is selected through
+// --profile or MANIFOLD_PROFILE.
+func SetActiveProfile(name string) error {
+	f, err := loadFile()
+	if err != nil {
+		return err
+	}
+
+	if err := migrateProfiles(f); err != nil {
+		return err
+	}
+
+	if _, err := f.GetSection(name); err != nil && name != DefaultProfile {
+		return ErrProfileNotFound
+	}
+
+	f.Section(ini.DEFAULT_SECTION).Key(profileKey).SetValue(name)
+	return writeFile(f)
+}
+
+// RemoveProfile deletes a profile along with its credentials. The active
+// profile can't be removed.
+func RemoveProfile(name string) error {
+	f, err := loadFile()
+	if err != nil {
+		return err
+	}
+
+	if err := migrateProfiles(f); err != nil {
+		return err
+	}
+
+	sec, err := f.GetSection(name)
+	if err != nil {
+		return ErrProfileNotFound
+	}
+
+	if name == storedProfile(f) {
+		return ErrActiveProfile
+	}
+
+	if store := sec.Key("token_store").String(); store != "" {
+		s, err := openStore(store)
+		if err != nil {
+			return err
+		}
+
+		if err := s.Delete(name); err != nil {
+			return err
+		}
+	}
+
+	f.DeleteSection(name)
+	return writeFile(f)
+}
+
+// activeProfile returns the profile selected for this invocation, falling
+// back to the one stored in the file.
+func activeProfile(f *ini.File) string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+
+	if name := os.Getenv(EnvProfile); name != "" {
+		return name
+	}
+
+	return storedProfile(f)
+}
+
+func storedProfile(f *ini.File) string {
+	key, err := f.Section(ini.DEFAULT_SECTION).GetKey(profileKey)
+	if err != nil || key.String() == "" {
+		return DefaultProfile
+	}
+
+	return key.String()
+}
+
+// migrateProfiles moves the values of a config file written before profiles
+// existed into the default profile, and saves the file.
+func migrateProfiles(f *ini.File) error {
+	def := f.Section(ini.DEFAULT_SECTION)
+
+	var legacy []*ini.Key
+	for _, key := range def.Keys() {
+		if key.Name() != profileKey {
+			legacy = append(legacy, key)
+		}
+	}
+
+	if len(legacy) == 0 {
+		return nil
+	}
+
+	sec, err := f.NewSection(DefaultProfile)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range legacy {
+		if !sec.HasKey(key.Name()) {
+			if _, err := sec.NewKey(key.Name(), key.Value()); err != nil {
+				return err
+			}
+		}
+		def.DeleteKey(key.Name())
+	}
+
+	if !def.HasKey(profileKey) {
+		def.Key(profileKey).SetValue(DefaultProfile)
+	}
+
+	return writeFile(f)
+}
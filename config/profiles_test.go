@@ -0,0 +1,129 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func withHome(t *testing.T, rc string) func() {
+	dir, err := ioutil.TempDir("", "manifoldrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rc != "" {
+		err = ioutil.WriteFile(filepath.Join(dir, rcFilename), []byte(rc), requiredPermissions)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+	os.Unsetenv(EnvProfile)
+	UseProfile("")
+
+	return func() {
+		os.Setenv("HOME", home)
+		UseProfile("")
+		os.RemoveAll(dir)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	t.Run("migrates a single profile file", func(t *testing.T) {
+		defer withHome(t, "hostname = example.com\nauth_token = abc\n")()
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if cfg.Profile != DefaultProfile || cfg.AuthToken != "abc" || cfg.Hostname != "example.com" {
+			t.Errorf("Expected the migrated values, got %+v", cfg)
+		}
+
+		names, active, err := Profiles()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(names, []string{DefaultProfile}) || active != DefaultProfile {
+			t.Errorf("Expected only the default profile, got %v %s", names, active)
+		}
+	})
+
+	t.Run("keeps profiles apart", func(t *testing.T) {
+		defer withHome(t, "")()
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		cfg.AuthToken = "ours"
+		if err := cfg.Write(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if err := AddProfile("client", "client.example.com", ""); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := AddProfile("client", "", ""); err != ErrProfileExists {
+			t.Errorf("Expected ErrProfileExists, got %v", err)
+		}
+
+		UseProfile("client")
+		cfg, err = Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if cfg.AuthToken != "" || cfg.Hostname != "client.example.com" {
+			t.Errorf("Expected the client profile, got %+v", cfg)
+		}
+		cfg.AuthToken = "theirs"
+		if err := cfg.Write(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		UseProfile("")
+		cfg, err = Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if cfg.AuthToken != "ours" {
+			t.Errorf("Expected the default profile token, got %q", cfg.AuthToken)
+		}
+
+		if err := SetActiveProfile("client"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := RemoveProfile("client"); err != ErrActiveProfile {
+			t.Errorf("Expected ErrActiveProfile, got %v", err)
+		}
+		if err := RemoveProfile(DefaultProfile); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		cfg, err = Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if cfg.Profile != "client" || cfg.AuthToken != "theirs" {
+			t.Errorf("Expected the client profile, got %+v", cfg)
+		}
+	})
+
+	t.Run("fails on unknown profiles", func(t *testing.T) {
+		defer withHome(t, "")()
+
+		UseProfile("missing")
+		if _, err := Load(); err != ErrProfileNotFound {
+			t.Errorf("Expected ErrProfileNotFound, got %v", err)
+		}
+		if err := SetActiveProfile("missing"); err != ErrProfileNotFound {
+			t.Errorf("Expected ErrProfileNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/manifoldco/manifold-cli/secrets"
+)
+
+const secretsDirectory = ".manifoldsecrets"
+
+// openStore opens the named secret store, keeping the file store next to
+// ~/.manifoldrc.
+func openStore(name string) (secrets.Store, error) {
+	home, err := UserHome()
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets.Open(name, filepath.Join(home, secretsDirectory))
+}
+
+// loadToken reads AuthToken from the secret store of the profile.
+func (c *Config) loadToken() error {
+	if c.TokenStore == "" || c.TokenStore == secrets.Auto {
+		return nil
+	}
+
+	s, err := openStore(c.TokenStore)
+	if err != nil {
+		return err
+	}
+
+	token, err := s.Get(c.Profile)
+	switch err {
+	case nil:
+	case secrets.ErrNotFound:
+		token = ""
+	default:
+		return err
+	}
+
+	c.AuthToken = token
+	c.storedToken = token
+	return nil
+}
+
+// storeToken saves AuthToken to the secret store when it has changed. An
+// automatic store is resolved to the one actually used.
+func (c *Config) storeToken(profile string) error {
+	if c.TokenStore != secrets.Auto && c.AuthToken == c.storedToken {
+		return nil
+	}
+
+	s, err := openStore(c.TokenStore)
+	if err != nil {
+		return err
+	}
+
+	if c.AuthToken == "" {
+		err = s.Delete(profile)
+	} else {
+		err = s.Set(profile, c.AuthToken)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.TokenStore = s.Name()
+	c.storedToken = c.AuthToken
+	return nil
+}
+
+// MoveToken moves AuthToken out of ~/.manifoldrc, or out of its current
+// secret store, into the named secret store.
+func (c *Config) MoveToken(name string) error {
+	s, err := openStore(name)
+	if err != nil {
+		return err
+	}
+
+	previous := c.TokenStore
+	if s.Name() == previous {
+		return nil
+	}
+
+	c.TokenStore = s.Name()
+	c.storedToken = ""
+	if err := c.Write(); err != nil {
+		return err
+	}
+
+	// A token kept in plaintext was overwritten by Write
+	if previous == "" || previous == secrets.Auto {
+		return nil
+	}
+
+	prev, err := openStore(previous)
+	if err != nil {
+		return err
+	}
+
+	return prev.Delete(c.Profile)
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/manifoldco/manifold-cli/secrets"
+)
+
+func readRC(t *testing.T) string {
+	b, err := ioutil.ReadFile(filepath.Join(os.Getenv("HOME"), rcFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestTokens(t *testing.T) {
+	bus := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	os.Unsetenv("DBUS_SESSION_BUS_ADDRESS")
+	defer os.Setenv("DBUS_SESSION_BUS_ADDRESS", bus)
+
+	t.Run("stores new tokens outside the rc file", func(t *testing.T) {
+		defer withHome(t, "")()
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		cfg.AuthToken = "secret-token"
+		if err := cfg.Write(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if rc := readRC(t); strings.Contains(rc, "secret-token") {
+			t.Errorf("Expected the token to be removed from the rc file, got:\n%s", rc)
+		}
+
+		cfg, err = Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if cfg.TokenStore != secrets.File || cfg.AuthToken != "secret-token" {
+			t.Errorf("Expected the token in the file store, got %+v", cfg)
+		}
+	})
+
+	t.Run("migrates plaintext tokens", func(t *testing.T) {
+		defer withHome(t, "[default]\nauth_token = secret-token\n")()
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if cfg.TokenStore != "" || cfg.AuthToken != "secret-token" {
+			t.Fatalf("Expected a plaintext token, got %+v", cfg)
+		}
+
+		if err := cfg.MoveToken(secrets.Auto); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if rc := readRC(t); strings.Contains(rc, "secret-token") {
+			t.Errorf("Expected the token to be removed from the rc file, got:\n%s", rc)
+		}
+
+		cfg, err = Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if cfg.TokenStore != secrets.File || cfg.AuthToken != "secret-token" {
+			t.Errorf("Expected the token in the file store, got %+v", cfg)
+		}
+	})
+
+	t.Run("loads logged out when the store is unreadable", func(t *testing.T) {
+		defer withHome(t, "[default]\ntoken_store = file\n")()
+
+		dir := filepath.Join(os.Getenv("HOME"), secretsDirectory)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "secrets"), []byte("corrupt"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if cfg.AuthToken != "" {
+			t.Errorf("Expected to be logged out, got %+v", cfg)
+		}
+	})
+}
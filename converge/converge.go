@@ -0,0 +1,176 @@
+// Package converge computes the changes needed to bring the resources of a
+// team in line with the resources declared in a .manifold.yml file.
+package converge
+
+import (
+	"fmt"
+	"sort"
+)
+
+// State describes a resource, either as declared or as it exists.
+type State struct {
+	Name    string
+	Title   string
+	Product string
+	Plan    string
+	Region  string
+	Project string
+	Aliases map[string]string
+	Config  map[string]string
+}
+
+// Custom returns true for custom resources, which have no product.
+func (s *State) Custom() bool {
+	return s.Product == ""
+}
+
+// Action is the kind of change applied to a resource.
+type Action string
+
+const (
+	// Create provisions a new resource
+	Create Action = "create"
+
+	// Move changes the project of a resource
+	Move Action = "move"
+
+	// Resize changes the plan of a resource
+	Resize Action = "resize"
+
+	// Update changes credential aliases or custom config values
+	Update Action = "update"
+
+	// Delete deprovisions a resource which is no longer declared
+	Delete Action = "delete"
+)
+
+// Change is a single step towards the declared state.
+type Change struct {
+	Action  Action
+	Name    string
+	Desired *State
+	Current *State
+
+	// Aliases and Config hold the values to set for an Update
+	Aliases map[string]string
+	Config  map[string]string
+}
+
+// Plan returns the changes needed to converge the current resources to the
+// desired ones. Creations come first and deletions last.
+//
+// Only the aliases and config keys which are declared are compared; other
+// keys are left untouched. When prune is true, resources which aren't
+// declared but belong to one of the declared projects are deleted.
+func Plan(desired, current []State, prune bool) ([]Change, error) {
+	byName := make(map[string]*State)
+	for i := range current {
+		byName[current[i].Name] = &current[i]
+	}
+
+	declared := make(map[string]bool)
+	projects := make(map[string]bool)
+
+	var creates, updates, deletes []Change
+	for i := range desired {
+		d := &desired[i]
+		if d.Name == "" {
+			return nil, fmt.Errorf("A name is required for every declared resource")
+		}
+		if declared[d.Name] {
+			return nil, fmt.Errorf("Resource %q is declared more than once", d.Name)
+		}
+		if d.Custom() && (d.Plan != "" || d.Region != "") {
+			return nil, fmt.Errorf("Resource %q has a plan or region but no product", d.Name)
+		}
+		if !d.Custom() && len(d.Config) > 0 {
+			return nil, fmt.Errorf("Resource %q has config values but only custom resources can be configured", d.Name)
+		}
+
+		declared[d.Name] = true
+		if d.Project != "" {
+			projects[d.Project] = true
+		}
+
+		c, ok := byName[d.Name]
+		if !ok {
+			creates = append(creates, Change{Action: Create, Name: d.Name, Desired: d})
+			continue
+		}
+
+		changes, err := diff(d, c)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, changes...)
+	}
+
+	if prune {
+		for i := range current {
+			c := &current[i]
+			if declared[c.Name] || c.Project == "" || !projects[c.Project] {
+				continue
+			}
+
+			deletes = append(deletes, Change{Action: Delete, Name: c.Name, Current: c})
+		}
+
+		sort.Slice(deletes, func(i, j int) bool {
+			return deletes[i].Name < deletes[j].Name
+		})
+	}
+
+	changes := append(creates, updates...)
+	return append(changes, deletes...), nil
+}
+
+func diff(d, c *State) ([]Change, error) {
+	if d.Custom() != c.Custom() {
+		return nil, fmt.Errorf("Resource %q cannot be converted to or from a custom resource", d.Name)
+	}
+	if d.Product != c.Product {
+		return nil, fmt.Errorf("Resource %q cannot change product from %q to %q", d.Name, c.Product, d.Product)
+	}
+	if d.Region != "" && d.Region != c.Region {
+		return nil, fmt.Errorf("Resource %q cannot change region from %q to %q", d.Name, c.Region, d.Region)
+	}
+
+	var changes []Change
+	if d.Project != c.Project {
+		changes = append(changes, Change{Action: Move, Name: d.Name, Desired: d, Current: c})
+	}
+	if !d.Custom() && d.Plan != "" && d.Plan != c.Plan {
+		changes = append(changes, Change{Action: Resize, Name: d.Name, Desired: d, Current: c})
+	}
+
+	aliases := changedKeys(d.Aliases, c.Aliases)
+	config := changedKeys(d.Config, c.Config)
+	if len(aliases) > 0 || len(config) > 0 {
+		changes = append(changes, Change{
+			Action:  Update,
+			Name:    d.Name,
+			Desired: d,
+			Current: c,
+			Aliases: aliases,
+			Config:  config,
+		})
+	}
+
+	return changes, nil
+}
+
+func changedKeys(desired, current map[string]string) map[string]string {
+	var out map[string]string
+	for k, v := range desired {
+		if cv, ok := current[k]; ok && cv == v {
+			continue
+		}
+
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[k] = v
+	}
+
+	return out
+}
@@ -0,0 +1,120 @@
+package converge
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	current := []State{
+		{Name: "db", Product: "postgres", Plan: "small", Region: "aws::us-east-1", Project: "api"},
+		{Name: "cache", Product: "redis", Plan: "small", Region: "aws::us-east-1", Project: "api",
+			Aliases: map[string]string{"REDIS_URL": "CACHE_URL"}},
+		{Name: "settings", Project: "api", Config: map[string]string{"MODE": "dev", "DEBUG": "1"}},
+		{Name: "old", Product: "logs", Plan: "free", Region: "aws::us-east-1", Project: "api"},
+		{Name: "elsewhere", Product: "logs", Plan: "free", Region: "aws::us-east-1", Project: "web"},
+	}
+
+	tcs := []struct {
+		scenario string
+		desired  []State
+		prune    bool
+		actions  []Action
+		names    []string
+		err      bool
+	}{
+		{
+			scenario: "when everything is in sync",
+			desired: []State{
+				{Name: "db", Product: "postgres", Plan: "small", Project: "api"},
+				{Name: "cache", Product: "redis", Plan: "small", Project: "api",
+					Aliases: map[string]string{"REDIS_URL": "CACHE_URL"}},
+			},
+		},
+		{
+			scenario: "when resources need changes",
+			desired: []State{
+				{Name: "db", Product: "postgres", Plan: "large", Project: "web"},
+				{Name: "logs", Product: "logs", Plan: "free", Project: "api"},
+				{Name: "settings", Project: "api", Config: map[string]string{"MODE": "prod"}},
+			},
+			actions: []Action{Create, Move, Resize, Update},
+			names:   []string{"logs", "db", "db", "settings"},
+		},
+		{
+			scenario: "when pruning undeclared resources",
+			desired: []State{
+				{Name: "db", Product: "postgres", Plan: "small", Project: "api"},
+			},
+			prune:   true,
+			actions: []Action{Delete, Delete, Delete},
+			names:   []string{"cache", "old", "settings"},
+		},
+		{
+			scenario: "when changing the product",
+			desired:  []State{{Name: "db", Product: "mysql", Plan: "small", Project: "api"}},
+			err:      true,
+		},
+		{
+			scenario: "when changing the region",
+			desired:  []State{{Name: "db", Product: "postgres", Region: "gcp::us-east1", Project: "api"}},
+			err:      true,
+		},
+		{
+			scenario: "when configuring a catalog resource",
+			desired:  []State{{Name: "db", Product: "postgres", Config: map[string]string{"A": "b"}}},
+			err:      true,
+		},
+		{
+			scenario: "when declaring a resource twice",
+			desired:  []State{{Name: "x"}, {Name: "x"}},
+			err:      true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.scenario, func(t *testing.T) {
+			changes, err := Plan(tc.desired, current, tc.prune)
+			if tc.err {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", changes)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			var actions []Action
+			var names []string
+			for _, c := range changes {
+				actions = append(actions, c.Action)
+				names = append(names, c.Name)
+			}
+
+			if !reflect.DeepEqual(actions, tc.actions) || !reflect.DeepEqual(names, tc.names) {
+				t.Errorf("Expected %v %v, got %v %v", tc.actions, tc.names, actions, names)
+			}
+		})
+	}
+}
+
+func TestPlanUpdate(t *testing.T) {
+	current := []State{
+		{Name: "settings", Config: map[string]string{"MODE": "dev", "DEBUG": "1"}},
+	}
+	desired := []State{
+		{Name: "settings", Config: map[string]string{"MODE": "prod", "DEBUG": "1", "NEW": "x"}},
+	}
+
+	changes, err := Plan(desired, current, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := map[string]string{"MODE": "prod", "NEW": "x"}
+	if len(changes) != 1 || !reflect.DeepEqual(changes[0].Config, expected) {
+		t.Errorf("Expected config %v, got %v", expected, changes)
+	}
+}
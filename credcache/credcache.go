@@ -0,0 +1,211 @@
+// Package credcache stores the last fetched credential set of a team or
+// project on disk, so `run` and `export` keep working without network access.
+//
+// Entries are sealed with sealbox, using a random secret kept next to the
+// entries.
+package credcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/sealbox"
+)
+
+const (
+	directory      = ".manifoldcache"
+	secretFilename = "secret"
+	entryExt       = ".cache"
+	dirPermissions = 0700
+
+	// DefaultTTL is how long entries are served when no TTL is configured
+	DefaultTTL = 24 * time.Hour
+)
+
+// ErrNotFound is returned when there is no entry for a scope
+var ErrNotFound = errors.New("No cached credentials found")
+
+// ErrExpired is returned when the entry for a scope is older than the TTL
+var ErrExpired = errors.New("Cached credentials have expired")
+
+// ErrCorrupted is returned when an entry can't be decrypted
+var ErrCorrupted = errors.New("Cached credentials could not be decrypted")
+
+// Scope identifies a cached credential set.
+type Scope struct {
+	Profile  string
+	Hostname string
+	Team     string
+	Project  string
+}
+
+// Resource holds the credentials of a single resource.
+type Resource struct {
+	Name   string            `json:"name"`
+	Values map[string]string `json:"values"`
+}
+
+// Entry is a cached credential set.
+type Entry struct {
+	FetchedAt time.Time  `json:"fetched_at"`
+	Resources []Resource `json:"resources"`
+}
+
+// Flatten returns the credentials of every resource in a single map.
+func Flatten(resources []Resource) map[string]string {
+	out := make(map[string]string)
+	for _, r := range resources {
+		for name, value := range r.Values {
+			out[name] = value
+		}
+	}
+
+	return out
+}
+
+// Cache reads and writes encrypted entries inside a directory.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// New returns a Cache stored in dir. Entries older than ttl are not served.
+func New(dir string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// Path returns the default cache directory, ~/.manifoldcache
+func Path() (string, error) {
+	home, err := config.UserHome()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, directory), nil
+}
+
+// Get returns the entry for the scope. ErrNotFound is returned if nothing was
+// cached, and ErrExpired if the entry is older than the TTL.
+func (c *Cache) Get(scope Scope) (*Entry, error) {
+	b, err := ioutil.ReadFile(c.entryPath(scope))
+	switch {
+	case err == nil:
+	case os.IsNotExist(err):
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+
+	secret, err := c.secret(false)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := sealbox.Open(secret, b)
+	if err != nil {
+		if err == sealbox.ErrCorrupted {
+			err = ErrCorrupted
+		}
+		return nil, err
+	}
+
+	e := &Entry{}
+	if err := json.Unmarshal(plain, e); err != nil {
+		return nil, ErrCorrupted
+	}
+
+	if time.Since(e.FetchedAt) > c.ttl {
+		return nil, ErrExpired
+	}
+
+	return e, nil
+}
+
+// Put encrypts and stores the resources for the scope, replacing any
+// previous entry.
+func (c *Cache) Put(scope Scope, resources []Resource) error {
+	if err := os.MkdirAll(c.dir, dirPermissions); err != nil {
+		return err
+	}
+
+	secret, err := c.secret(true)
+	if err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(&Entry{
+		FetchedAt: time.Now().UTC(),
+		Resources: resources,
+	})
+	if err != nil {
+		return err
+	}
+
+	b, err := sealbox.Seal(secret, plain)
+	if err != nil {
+		return err
+	}
+
+	path := c.entryPath(scope)
+	if err := os.MkdirAll(filepath.Dir(path), dirPermissions); err != nil {
+		return err
+	}
+
+	return sealbox.WriteFile(path, b)
+}
+
+// Clear removes every entry along with the local secret.
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.dir)
+}
+
+// ClearProfile removes the entries of a single profile, leaving the entries
+// of other profiles untouched.
+func (c *Cache) ClearProfile(profile string) error {
+	return os.RemoveAll(c.profileDir(profile))
+}
+
+// profileDir returns the directory holding the entries of a profile. Like
+// entries, it's named after a hash so profile names aren't leaked.
+func (c *Cache) profileDir(profile string) string {
+	sum := sha256.Sum256([]byte(profile))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:8]))
+}
+
+// entryPath hashes the scope, so team and project names aren't leaked
+// through file names.
+func (c *Cache) entryPath(scope Scope) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		scope.Profile, scope.Hostname, scope.Team, scope.Project,
+	}, "\x00")))
+
+	return filepath.Join(c.profileDir(scope.Profile), hex.EncodeToString(sum[:])+entryExt)
+}
+
+// secret reads the local secret, generating it when create is true and it
+// doesn't exist yet.
+func (c *Cache) secret(create bool) ([]byte, error) {
+	b, err := sealbox.ReadKey(filepath.Join(c.dir, secretFilename), create)
+	switch {
+	case err == nil:
+		return b, nil
+	case err == sealbox.ErrCorrupted:
+		return nil, ErrCorrupted
+	case os.IsNotExist(err):
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,123 @@
+package credcache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/manifold-cli/sealbox"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := New(filepath.Join(dir, "cache"), time.Hour)
+	scope := Scope{Hostname: "manifold.co", Team: "acme", Project: "api"}
+
+	t.Run("when nothing is cached", func(t *testing.T) {
+		if _, err := c.Get(scope); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	resources := []Resource{
+		{Name: "db", Values: map[string]string{"DATABASE_URL": "postgres://db"}},
+		{Name: "cache", Values: map[string]string{"REDIS_URL": "redis://cache"}},
+	}
+	if err := c.Put(scope, resources); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	t.Run("when reading an entry", func(t *testing.T) {
+		e, err := c.Get(scope)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		creds := Flatten(e.Resources)
+		if len(creds) != 2 || creds["DATABASE_URL"] != "postgres://db" {
+			t.Errorf("Unexpected credentials: %v", creds)
+		}
+	})
+
+	t.Run("when reading another scope", func(t *testing.T) {
+		if _, err := c.Get(Scope{Hostname: "manifold.co", Team: "acme"}); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("when the entry is stored on disk", func(t *testing.T) {
+		fi, err := os.Stat(c.entryPath(scope))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != sealbox.FilePermissions {
+			t.Errorf("Expected permissions %o, got %o", sealbox.FilePermissions, fi.Mode().Perm())
+		}
+
+		b, _ := ioutil.ReadFile(c.entryPath(scope))
+		if bytes.Contains(b, []byte("postgres://db")) {
+			t.Error("Expected entry to be encrypted")
+		}
+	})
+
+	t.Run("when the entry has expired", func(t *testing.T) {
+		expired := New(c.dir, time.Nanosecond)
+		time.Sleep(time.Millisecond)
+		if _, err := expired.Get(scope); err != ErrExpired {
+			t.Errorf("Expected ErrExpired, got %v", err)
+		}
+	})
+
+	t.Run("when the secret changes", func(t *testing.T) {
+		other := New(filepath.Join(dir, "other"), time.Hour)
+		if err := other.Put(scope, resources); err != nil {
+			t.Fatal(err)
+		}
+
+		b, _ := ioutil.ReadFile(other.entryPath(scope))
+		if err := ioutil.WriteFile(c.entryPath(scope), b, sealbox.FilePermissions); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := c.Get(scope); err != ErrCorrupted {
+			t.Errorf("Expected ErrCorrupted, got %v", err)
+		}
+	})
+
+	t.Run("when clearing a profile", func(t *testing.T) {
+		work := Scope{Profile: "work", Hostname: "manifold.co", Team: "acme"}
+		if err := c.Put(work, resources); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Put(scope, resources); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.ClearProfile("work"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Get(work); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if _, err := c.Get(scope); err != nil {
+			t.Errorf("Expected other profiles to be kept, got %v", err)
+		}
+	})
+
+	t.Run("when clearing the cache", func(t *testing.T) {
+		if err := c.Clear(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Get(scope); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
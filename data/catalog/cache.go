@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+
+	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/credcache"
+
+	catalogModels "github.com/manifoldco/manifold-cli/generated/catalog/models"
+)
+
+// DefaultTTL is how long the catalog is served from disk before it's
+// refreshed.
+const DefaultTTL = 24 * time.Hour
+
+const cacheVersion = 1
+
+// refreshBackoff is how long commands wait on a refresh started by another
+// command before starting their own.
+const refreshBackoff = time.Minute
+
+// Refresh is called when a stale catalog is served, to refresh it without
+// the command waiting on the API. A goroutine wouldn't outlive short
+// commands, so the CLI refreshes it from a detached process. Nothing is
+// refreshed when it's nil.
+var Refresh func()
+
+// snapshot is the catalog as written to disk.
+type snapshot struct {
+	Version   int                      `json:"version"`
+	FetchedAt time.Time                `json:"fetched_at"`
+	Products  []*catalogModels.Product `json:"products"`
+	Plans     []*catalogModels.Plan    `json:"plans"`
+	Regions   []*catalogModels.Region  `json:"regions"`
+}
+
+// cache reads and writes the catalog of a single Manifold host.
+type cache struct {
+	path string
+	ttl  time.Duration
+}
+
+// openCache returns the cache of the host of the active profile, or nil when
+// it's disabled through a catalog_cache_ttl of 0.
+func openCache() (*cache, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := DefaultTTL
+	if cfg.CatalogCacheTTL != "" {
+		ttl, err = time.ParseDuration(cfg.CatalogCacheTTL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if ttl <= 0 {
+		return nil, nil
+	}
+
+	dir, err := credcache.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.Replace(cfg.Hostname, ":", "_", -1) + ".json"
+	return &cache{path: filepath.Join(dir, "catalog", name), ttl: ttl}, nil
+}
+
+// read returns the cached snapshot, or nil when there is none.
+func (c *cache) read() *snapshot {
+	b, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return nil
+	}
+
+	s := &snapshot{}
+	if err := json.Unmarshal(b, s); err != nil || s.Version != cacheVersion {
+		return nil
+	}
+
+	return s
+}
+
+func (c *cache) fresh(s *snapshot) bool {
+	return time.Since(s.FetchedAt) < c.ttl
+}
+
+// write replaces the snapshot atomically, so concurrent commands never read
+// a partial catalog.
+func (c *cache) write(cat *Catalog) error {
+	s := &snapshot{
+		Version:   cacheVersion,
+		FetchedAt: time.Now().UTC(),
+	}
+	for _, p := range cat.products {
+		s.Products = append(s.Products, p)
+	}
+	for _, p := range cat.plans {
+		s.Plans = append(s.Plans, p)
+	}
+	for _, r := range cat.regions {
+		s.Regions = append(s.Regions, r)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(c.path), ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.path)
+}
+
+// load fills the catalog with the snapshot.
+func (cat *Catalog) load(s *snapshot) {
+	cat.products = make(map[manifold.ID]*catalogModels.Product, len(s.Products))
+	for _, p := range s.Products {
+		cat.products[p.ID] = p
+	}
+
+	cat.plans = make(map[manifold.ID]*catalogModels.Plan, len(s.Plans))
+	for _, p := range s.Plans {
+		cat.plans[p.ID] = p
+	}
+
+	cat.regions = make(map[manifold.ID]*catalogModels.Region, len(s.Regions))
+	for _, r := range s.Regions {
+		cat.regions[r.ID] = r
+	}
+
+	cat.fetchedAt = s.FetchedAt
+}
+
+// claimRefresh returns whether a stale catalog should be refreshed by this
+// command, so concurrent commands don't all start a refresh. A refresh which
+// didn't complete is retried after refreshBackoff.
+func (c *cache) claimRefresh() bool {
+	marker := c.path + ".refresh"
+	if fi, err := os.Stat(marker); err == nil && time.Since(fi.ModTime()) < refreshBackoff {
+		return false
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return false
+	}
+
+	return ioutil.WriteFile(marker, nil, 0600) == nil
+}
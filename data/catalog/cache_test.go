@@ -0,0 +1,150 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+
+	"github.com/manifoldco/manifold-cli/config"
+
+	catalogModels "github.com/manifoldco/manifold-cli/generated/catalog/models"
+)
+
+func withHome(t *testing.T, rc string) func() {
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, ".manifoldrc"), []byte(rc), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+	os.Unsetenv(config.EnvProfile)
+
+	return func() {
+		os.Setenv("HOME", home)
+		os.RemoveAll(dir)
+	}
+}
+
+func writeSnapshot(t *testing.T, c *cache, s *snapshot) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(c.path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	defer withHome(t, "[default]\ncatalog_cache_ttl = 1h\n")()
+
+	refreshed := 0
+	defer func() { Refresh = nil }()
+	Refresh = func() { refreshed++ }
+
+	c, err := openCache()
+	if err != nil || c == nil {
+		t.Fatalf("Expected a cache, got %v", err)
+	}
+	if c.ttl != time.Hour {
+		t.Errorf("Expected the configured TTL, got %s", c.ttl)
+	}
+
+	id, err := manifold.NewID(idtype.Product)
+	if err != nil {
+		t.Fatal(err)
+	}
+	products := []*catalogModels.Product{{ID: id}}
+
+	t.Run("without a snapshot", func(t *testing.T) {
+		if s := c.read(); s != nil {
+			t.Errorf("Expected no snapshot, got %+v", s)
+		}
+	})
+
+	t.Run("with a snapshot of another version", func(t *testing.T) {
+		writeSnapshot(t, c, &snapshot{Version: cacheVersion + 1, FetchedAt: time.Now()})
+		if s := c.read(); s != nil {
+			t.Errorf("Expected the snapshot to be ignored, got %+v", s)
+		}
+	})
+
+	t.Run("with a written catalog", func(t *testing.T) {
+		if err := c.write(&Catalog{products: map[manifold.ID]*catalogModels.Product{id: products[0]}}); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		s := c.read()
+		if s == nil || len(s.Products) != 1 || s.Products[0].ID != id {
+			t.Fatalf("Expected the written products, got %+v", s)
+		}
+		if !c.fresh(s) {
+			t.Error("Expected a new snapshot to be fresh")
+		}
+
+		cat, err := New(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if _, err := cat.GetProduct(id); err != nil {
+			t.Errorf("Expected the product to be served from disk, got %s", err)
+		}
+		if refreshed != 0 {
+			t.Errorf("Expected a fresh catalog not to be refreshed, got %d refreshes", refreshed)
+		}
+	})
+
+	t.Run("with a stale snapshot", func(t *testing.T) {
+		fetchedAt := time.Now().Add(-2 * time.Hour).UTC()
+		writeSnapshot(t, c, &snapshot{
+			Version:   cacheVersion,
+			FetchedAt: fetchedAt,
+			Products:  products,
+		})
+
+		cat, err := New(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if _, err := cat.GetProduct(id); err != nil {
+			t.Errorf("Expected the stale product to be served, got %s", err)
+		}
+		if !cat.FetchedAt().Equal(fetchedAt) {
+			t.Errorf("Expected the stale fetch time, got %s", cat.FetchedAt())
+		}
+		if refreshed != 1 {
+			t.Errorf("Expected a refresh to be started, got %d", refreshed)
+		}
+
+		if _, err := New(context.Background(), nil); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if refreshed != 1 {
+			t.Errorf("Expected a single refresh while one is pending, got %d", refreshed)
+		}
+	})
+}
+
+func TestCacheDisabled(t *testing.T) {
+	defer withHome(t, "[default]\ncatalog_cache_ttl = 0\n")()
+
+	c, err := openCache()
+	if err != nil || c != nil {
+		t.Errorf("Expected the cache to be disabled, got %+v %v", c, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/manifoldco/go-manifold"
 	hierr "github.com/reconquest/hierr-go"
@@ -14,12 +15,19 @@ import (
 	catalogModels "github.com/manifoldco/manifold-cli/generated/catalog/models"
 )
 
-// Catalog represents a local in memory cache of catalog data
+// Catalog represents a local in memory cache of catalog data, persisted to
+// ~/.manifoldcache/catalog between commands
 type Catalog struct {
-	client   *catalogClient.Catalog
-	products map[manifold.ID]*catalogModels.Product
-	plans    map[manifold.ID]*catalogModels.Plan
-	regions  map[manifold.ID]*catalogModels.Region
+	client    *catalogClient.Catalog
+	products  map[manifold.ID]*catalogModels.Product
+	plans     map[manifold.ID]*catalogModels.Plan
+	regions   map[manifold.ID]*catalogModels.Region
+	fetchedAt time.Time
+}
+
+// FetchedAt returns when the catalog data was fetched from the API
+func (c *Catalog) FetchedAt() time.Time {
+	return c.fetchedAt
 }
 
 // GetProduct returns the Product data model based on the provided id
@@ -103,16 +111,60 @@ func (c *Catalog) Regions() []*catalogModels.Region {
 }
 
 // Sync attempts to update the catalog and returns an error if anything went
-// wrong
+// wrong. The updated catalog is persisted.
 func (c *Catalog) Sync(ctx context.Context) error {
-	_, err := updateCatalog(ctx, c)
-	return err
+	if _, err := updateCatalog(ctx, c); err != nil {
+		return err
+	}
+
+	cache, err := openCache()
+	if err != nil || cache == nil {
+		return err
+	}
+	return cache.write(c)
 }
 
-// New creates a new instance of a Catalog struct and populates it with data
-// from the API using the provided Catalog API client and context
+// New creates a new instance of a Catalog struct using the provided Catalog
+// API client and context.
+//
+// The catalog persisted by a previous command is used until its TTL expires.
+// A stale catalog is still used while it's refreshed in the background, so
+// commands keep working offline. The API is only waited on without a
+// persisted catalog.
 func New(ctx context.Context, client *catalogClient.Catalog) (*Catalog, error) {
-	return updateCatalog(ctx, &Catalog{client: client})
+	c := &Catalog{client: client}
+
+	cache, err := openCache()
+	if err != nil || cache == nil {
+		return updateCatalog(ctx, c)
+	}
+
+	s := cache.read()
+	if s == nil {
+		if _, err := updateCatalog(ctx, c); err != nil {
+			return nil, err
+		}
+		cache.write(c)
+		return c, nil
+	}
+
+	c.load(s)
+	if !cache.fresh(s) && Refresh != nil && cache.claimRefresh() {
+		Refresh()
+	}
+
+	return c, nil
+}
+
+// Fetch returns a catalog fetched from the API, replacing the persisted
+// catalog.
+func Fetch(ctx context.Context, client *catalogClient.Catalog) (*Catalog, error) {
+	c := &Catalog{client: client}
+	if err := c.Sync(ctx); err != nil {
+		return nil, err
+	}
+
+	return c, nil
 }
 
 func updateCatalog(ctx context.Context, cache *Catalog) (*Catalog, error) {
@@ -158,5 +210,7 @@ func updateCatalog(ctx context.Context, cache *Catalog) (*Catalog, error) {
 		cache.regions[region.ID] = region
 	}
 
+	cache.fetchedAt = time.Now().UTC()
+
 	return cache, nil
 }
@@ -37,5 +37,8 @@ func (c *Catalog) FetchPlanById(ctx context.Context, planID manifold.ID) (*catal
 		return nil, hierr.Errorf(err,
 			"Failed to fetch the latest product plan data")
 	}
+
+	// Remember plans missing from the catalog, such as unlisted plans
+	c.plans[plan.Payload.ID] = plan.Payload
 	return plan.Payload, nil
 }
@@ -0,0 +1,391 @@
+// Package devserver implements an in-memory Manifold API from the bundled
+// OpenAPI specs, to develop and test the CLI without a real account.
+//
+// Every service is served under its own path, such as /identity/v1/self.
+// Collections are stored generically, with a few handlers for endpoints such
+// as logging in and provisioning operations.
+package devserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// specsDirectory is the name of the directory holding the bundled specs.
+const specsDirectory = "specs"
+
+// handler serves a route which can't be stored generically.
+type handler func(s *Server, r *http.Request, params map[string]string) (int, interface{})
+
+// Server serves the endpoints of every spec from memory.
+type Server struct {
+	mu       sync.Mutex
+	services map[string][]*route
+	handlers map[string]handler
+	store    *store
+	tokens   map[string]string
+}
+
+// SpecsDir returns the bundled specs directory, which releases ship next to
+// the binary. The specs directory of the working directory is used when
+// running from a checkout of the repository.
+func SpecsDir() (string, error) {
+	var dirs []string
+	if exe, err := os.Executable(); err == nil {
+		if exe, err := filepath.EvalSymlinks(exe); err == nil {
+			dirs = append(dirs, filepath.Join(filepath.Dir(exe), specsDirectory))
+		}
+	}
+	dirs = append(dirs, specsDirectory)
+
+	for _, dir := range dirs {
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("No bundled specs found next to the binary, use --specs")
+}
+
+// New returns a Server for the specs found in dir.
+func New(dir string) (*Server, error) {
+	services, err := loadSpecs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("No specs found in %s", dir)
+	}
+
+	s := &Server{
+		services: services,
+		store:    newStore(),
+		tokens:   make(map[string]string),
+	}
+	s.handlers = handlers()
+
+	return s, nil
+}
+
+// Seed adds objects to the collection of a service, such as users of the
+// identity service. Objects without an id are given one.
+func (s *Server) Seed(service, collection string, objs ...map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, o := range objs {
+		if _, err := s.store.put(service+"/"+collection, object(o)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SeedFile seeds the collections found in a JSON or YAML file, keyed by
+// service and collection:
+//
+//	identity/users:
+//	  - type: user
+//	    body:
+//	      email: dev@example.com
+func (s *Server) SeedFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string][]interface{}
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(b, &raw)
+	default:
+		err = yaml.Unmarshal(b, &raw)
+	}
+	if err != nil {
+		return err
+	}
+
+	for key, objs := range raw {
+		parts := strings.SplitN(key, "/", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("Invalid collection %q, expected service/collection", key)
+		}
+
+		for _, o := range objs {
+			m, ok := normalize(o).(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("Invalid object in %q", key)
+			}
+			if err := s.Seed(parts[0], parts[1], m); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP routes the request to the service named by the first segment of
+// the path.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := split(r.URL.Path)
+	if len(segments) < 2 || segments[1] != "v1" {
+		writeError(w, http.StatusNotFound, "not_found", "Unknown path")
+		return
+	}
+
+	service := segments[0]
+	routes, ok := s.services[service]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Unknown service "+service)
+		return
+	}
+
+	path := "/" + strings.Join(segments[2:], "/")
+	rt, params := match(routes, path)
+	if rt == nil {
+		writeError(w, http.StatusNotFound, "not_found", "Unknown path "+path)
+		return
+	}
+	if !rt.methods[r.Method] {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, ok := s.handlers[r.Method+" "+service+strings.TrimSuffix(rt.template, "/")]
+	if !ok {
+		h = generic(service, rt)
+	}
+
+	status, body := h(s, r, params)
+	write(w, status, body)
+}
+
+// generic stores the objects of a route by the static segments of its path.
+// Paths ending with a parameter address a single object, other paths with
+// parameters address a value nested under an object, such as its members.
+func generic(service string, rt *route) handler {
+	var statics []string
+	var last string
+	for _, seg := range rt.segments {
+		if isParam(seg) {
+			last = strings.Trim(seg, "{}")
+			continue
+		}
+		statics = append(statics, seg)
+	}
+
+	collection := service + "/" + strings.Join(statics, "/")
+	tail := rt.segments[len(rt.segments)-1]
+
+	switch {
+	case last == "":
+		return collectionHandler(collection)
+	case isParam(tail):
+		return itemHandler(collection, last)
+	default:
+		return nestedHandler(collection, last)
+	}
+}
+
+func collectionHandler(collection string) handler {
+	return func(s *Server, r *http.Request, _ map[string]string) (int, interface{}) {
+		switch r.Method {
+		case http.MethodGet:
+			return http.StatusOK, s.store.list(collection, r.URL.Query())
+		default:
+			o, err := readObject(r)
+			if err != nil {
+				return badRequest(err)
+			}
+
+			o = envelope(collection, o)
+			body := o.body()
+			if _, ok := body["created_at"]; !ok {
+				body["created_at"] = timestamp()
+				body["updated_at"] = body["created_at"]
+			}
+
+			o, err = s.store.put(collection, o)
+			if err != nil {
+				return internalError(err)
+			}
+			return http.StatusCreated, o
+		}
+	}
+}
+
+func itemHandler(collection, param string) handler {
+	return func(s *Server, r *http.Request, params map[string]string) (int, interface{}) {
+		id := params[param]
+		o := s.store.get(collection, param, id)
+
+		switch r.Method {
+		case http.MethodGet:
+			if o == nil {
+				return notFound()
+			}
+			return http.StatusOK, o
+		case http.MethodDelete:
+			if o == nil {
+				return notFound()
+			}
+			s.store.delete(collection, o.id())
+			return http.StatusNoContent, nil
+		}
+
+		in, err := readObject(r)
+		if err != nil {
+			return badRequest(err)
+		}
+
+		switch r.Method {
+		case http.MethodPatch:
+			if o == nil {
+				return notFound()
+			}
+			patch := envelope(collection, in).body()
+			for k, v := range patch {
+				o.body()[k] = v
+			}
+		default:
+			o = envelope(collection, in)
+			o["id"] = id
+		}
+
+		if _, err := s.store.put(collection, o); err != nil {
+			return internalError(err)
+		}
+		return http.StatusOK, o
+	}
+}
+
+func nestedHandler(collection, param string) handler {
+	return func(s *Server, r *http.Request, params map[string]string) (int, interface{}) {
+		key := collection + "/" + params[param]
+
+		switch r.Method {
+		case http.MethodGet:
+			v, ok := s.store.nested[key]
+			if !ok {
+				return http.StatusOK, []interface{}{}
+			}
+			return http.StatusOK, v
+		case http.MethodDelete:
+			delete(s.store.nested, key)
+			return http.StatusNoContent, nil
+		}
+
+		var v interface{}
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			return badRequest(err)
+		}
+
+		s.store.nested[key] = v
+		return http.StatusOK, v
+	}
+}
+
+// envelope wraps a request body in an object of the collection, unless it
+// already is one.
+func envelope(collection string, o object) object {
+	if _, ok := o["body"].(map[string]interface{}); ok {
+		if _, ok := o["version"]; !ok {
+			o["version"] = 1
+		}
+		return o
+	}
+
+	parts := strings.Split(collection, "/")
+	return object{
+		"type":    strings.TrimSuffix(parts[len(parts)-1], "s"),
+		"version": 1,
+		"body":    map[string]interface{}(o),
+	}
+}
+
+func readObject(r *http.Request) (object, error) {
+	o := object{}
+	if r.Body == nil {
+		return o, nil
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&o)
+	if err != nil && err.Error() != "EOF" {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// normalize converts the maps decoded from YAML to maps keyed by strings.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalize(val)
+		}
+		return m
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalize(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+type apiError struct {
+	Type    string   `json:"type"`
+	Message []string `json:"message"`
+}
+
+func notFound() (int, interface{}) {
+	return http.StatusNotFound, &apiError{Type: "not_found", Message: []string{"Not found"}}
+}
+
+func badRequest(err error) (int, interface{}) {
+	return http.StatusBadRequest, &apiError{Type: "bad_request", Message: []string{err.Error()}}
+}
+
+func unauthorized() (int, interface{}) {
+	return http.StatusUnauthorized, &apiError{Type: "unauthorized", Message: []string{"Unauthorized"}}
+}
+
+func internalError(err error) (int, interface{}) {
+	return http.StatusInternalServerError, &apiError{Type: "internal", Message: []string{err.Error()}}
+}
+
+func writeError(w http.ResponseWriter, status int, kind, msg string) {
+	write(w, status, &apiError{Type: kind, Message: []string{msg}})
+}
+
+func write(w http.ResponseWriter, status int, body interface{}) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
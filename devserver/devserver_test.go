@@ -0,0 +1,161 @@
+package devserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	s, err := New("../specs")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	dir, err := ioutil.TempDir("", "devserver")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	seed := filepath.Join(dir, "seed.yaml")
+	fixtures := "identity/users:\n  - type: user\n    body:\n      name: Dev\n      email: dev@example.com\n"
+	if err := ioutil.WriteFile(seed, []byte(fixtures), 0644); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := s.SeedFile(seed); err != nil {
+		t.Fatalf("Could not seed: %s", err)
+	}
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	var token string
+	do := func(method, path string, in interface{}, out interface{}) int {
+		var body bytes.Buffer
+		if in != nil {
+			json.NewEncoder(&body).Encode(in)
+		}
+
+		req, err := http.NewRequest(method, srv.URL+path, &body)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		defer res.Body.Close()
+
+		if out != nil {
+			json.NewDecoder(res.Body).Decode(out)
+		}
+		return res.StatusCode
+	}
+
+	t.Run("logs in", func(t *testing.T) {
+		login := map[string]string{}
+		if code := do("POST", "/identity/v1/tokens/login", map[string]string{"email": "dev@example.com"}, &login); code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", code)
+		}
+		if len(login["salt"]) != 22 {
+			t.Errorf("Expected a 22 character salt, got %q", login["salt"])
+		}
+
+		token = login["token"]
+		auth := object{}
+		do("POST", "/identity/v1/tokens/auth", map[string]string{"login_token_sig": "sig"}, &auth)
+		token, _ = auth.body()["token"].(string)
+
+		user := object{}
+		if code := do("GET", "/identity/v1/self", nil, &user); code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", code)
+		}
+		if user.body()["email"] != "dev@example.com" {
+			t.Errorf("Expected the seeded user, got %v", user)
+		}
+	})
+
+	t.Run("provisions resources", func(t *testing.T) {
+		project := object{}
+		if code := do("POST", "/marketplace/v1/projects", object{"body": map[string]interface{}{"label": "app"}}, &project); code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d", code)
+		}
+		if len(project.id()) != 29 {
+			t.Errorf("Expected an ID to be generated, got %q", project.id())
+		}
+
+		op := map[string]interface{}{
+			"type":    "operation",
+			"version": 1,
+			"body": map[string]interface{}{
+				"type":        "provision",
+				"state":       "provision",
+				"resource_id": "2000000000000000000000000000a",
+				"label":       "db",
+				"source":      "custom",
+				"project_id":  project.id(),
+			},
+		}
+		res := object{}
+		do("PUT", "/provisioning/v1/operations/1000000000000000000000000000a", op, &res)
+		if res.body()["state"] != "done" {
+			t.Errorf("Expected the operation to be done, got %v", res.body()["state"])
+		}
+
+		var resources []object
+		do("GET", "/marketplace/v1/resources/?project_id="+project.id(), nil, &resources)
+		if len(resources) != 1 || resources[0].body()["label"] != "db" {
+			t.Fatalf("Expected the provisioned resource, got %v", resources)
+		}
+
+		do("PATCH", "/marketplace/v1/resources/"+resources[0].id()+"/config", map[string]string{"KEY": "value"}, nil)
+
+		var creds []object
+		do("GET", "/marketplace/v1/credentials?project_id="+project.id(), nil, &creds)
+		if len(creds) != 1 {
+			t.Fatalf("Expected one credential, got %v", creds)
+		}
+		if values, _ := creds[0].body()["values"].(map[string]interface{}); values["KEY"] != "value" {
+			t.Errorf("Expected the patched config, got %v", creds[0].body())
+		}
+	})
+
+	t.Run("rejects unknown routes", func(t *testing.T) {
+		if code := do("GET", "/identity/v1/nothing", nil, nil); code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", code)
+		}
+		if code := do("DELETE", "/identity/v1/self", nil, nil); code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected 405, got %d", code)
+		}
+	})
+}
+
+func TestSpecsDir(t *testing.T) {
+	if _, err := SpecsDir(); err == nil {
+		t.Error("Expected no specs next to the test binary or in the package")
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(".."); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := SpecsDir()
+	if err != nil || dir != specsDirectory {
+		t.Errorf("Expected the specs of the working directory, got %q %v", dir, err)
+	}
+}
@@ -0,0 +1,300 @@
+package devserver
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// handlers returns the routes with behaviour beyond storing objects, keyed by
+// method, service and path template.
+func handlers() map[string]handler {
+	return map[string]handler{
+		"POST identity/tokens/login":              login,
+		"POST identity/tokens/auth":               authenticate,
+		"DELETE identity/tokens/{token}":          logout,
+		"GET identity/self":                       self,
+		"PUT provisioning/operations/{id}":        operate,
+		"GET marketplace/resources/{id}/config":   getConfig,
+		"PATCH marketplace/resources/{id}/config": patchConfig,
+		"GET marketplace/credentials":             credentials,
+	}
+}
+
+// login issues a login token to a seeded user. Passwords aren't checked, any
+// signature is accepted when exchanging the login token.
+func login(s *Server, r *http.Request, _ map[string]string) (int, interface{}) {
+	in, err := readObject(r)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	email, _ := in["email"].(string)
+	users := s.store.list("identity/users", map[string][]string{"email": {email}})
+	if email == "" || len(users) == 0 {
+		return badRequest(errors.New("No user with this email"))
+	}
+
+	token, err := randomString(32)
+	if err != nil {
+		return internalError(err)
+	}
+	salt, err := randomString(16)
+	if err != nil {
+		return internalError(err)
+	}
+
+	s.tokens["login:"+token] = users[0].id()
+	return http.StatusCreated, map[string]string{"token": token, "salt": salt}
+}
+
+func authenticate(s *Server, r *http.Request, _ map[string]string) (int, interface{}) {
+	userID, ok := s.tokens["login:"+bearer(r)]
+	if !ok {
+		return unauthorized()
+	}
+	delete(s.tokens, "login:"+bearer(r))
+
+	token, err := randomString(45)
+	if err != nil {
+		return internalError(err)
+	}
+	s.tokens[token] = userID
+
+	o, err := s.store.put("identity/tokens", object{
+		"type":    "auth_token",
+		"version": 1,
+		"body": map[string]interface{}{
+			"token":     token,
+			"user_id":   userID,
+			"mechanism": "eddsa",
+		},
+	})
+	if err != nil {
+		return internalError(err)
+	}
+
+	return http.StatusCreated, o
+}
+
+func logout(s *Server, _ *http.Request, params map[string]string) (int, interface{}) {
+	token := params["token"]
+	if _, ok := s.tokens[token]; !ok {
+		return unauthorized()
+	}
+
+	delete(s.tokens, token)
+	if o := s.store.get("identity/tokens", "token", token); o != nil {
+		s.store.delete("identity/tokens", o.id())
+	}
+
+	return http.StatusNoContent, nil
+}
+
+func self(s *Server, r *http.Request, _ map[string]string) (int, interface{}) {
+	user := s.user(r)
+	if user == nil {
+		return unauthorized()
+	}
+
+	return http.StatusOK, user
+}
+
+// user returns the user owning the bearer token of the request.
+func (s *Server) user(r *http.Request) object {
+	id, ok := s.tokens[bearer(r)]
+	if !ok {
+		return nil
+	}
+
+	return s.store.get("identity/users", "id", id)
+}
+
+// operate completes an operation as soon as it is received, applying it to
+// the resources and projects of the marketplace.
+func operate(s *Server, r *http.Request, params map[string]string) (int, interface{}) {
+	in, err := readObject(r)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	op := envelope("provisioning/operations", in)
+	op["id"] = params["id"]
+	body := op.body()
+
+	if err := s.apply(body); err != nil {
+		return badRequest(err)
+	}
+
+	body["state"] = "done"
+	if _, err := s.store.put("provisioning/operations", op); err != nil {
+		return internalError(err)
+	}
+
+	return http.StatusOK, op
+}
+
+func (s *Server) apply(body map[string]interface{}) error {
+	resourceID, _ := body["resource_id"].(string)
+	resource := s.store.get("marketplace/resources", "id", resourceID)
+
+	kind, _ := body["type"].(string)
+	if kind != "provision" && kind != "project_delete" && resource == nil {
+		return errors.New("No resource with this id")
+	}
+
+	switch kind {
+	case "provision":
+		now := timestamp()
+		resource = object{
+			"id":      resourceID,
+			"type":    "resource",
+			"version": 1,
+			"body": map[string]interface{}{
+				"created_at": now,
+				"updated_at": now,
+			},
+		}
+		for _, k := range []string{"name", "label", "app_name", "source", "product_id",
+			"plan_id", "region_id", "project_id", "user_id", "team_id"} {
+			if v, ok := body[k]; ok && v != nil {
+				resource.body()[k] = v
+			}
+		}
+		if _, ok := resource.body()["source"]; !ok {
+			resource.body()["source"] = "catalog"
+		}
+	case "resize":
+		resource.body()["plan_id"] = body["plan_id"]
+	case "move":
+		resource.body()["project_id"] = body["project_id"]
+	case "transfer":
+		owner, _ := body["new_owner_id"].(string)
+		delete(resource.body(), "user_id")
+		delete(resource.body(), "team_id")
+		if s.store.get("identity/teams", "id", owner) != nil {
+			resource.body()["team_id"] = owner
+		} else {
+			resource.body()["user_id"] = owner
+		}
+	case "deprovision":
+		s.store.delete("marketplace/resources", resourceID)
+		return nil
+	case "project_delete":
+		projectID, _ := body["project_id"].(string)
+		if !s.store.delete("marketplace/projects", projectID) {
+			return errors.New("No project with this id")
+		}
+		return nil
+	default:
+		return errors.New("Unknown operation type " + kind)
+	}
+
+	resource.body()["updated_at"] = timestamp()
+	_, err := s.store.put("marketplace/resources", resource)
+	return err
+}
+
+func getConfig(s *Server, _ *http.Request, params map[string]string) (int, interface{}) {
+	if s.store.get("marketplace/resources", "id", params["id"]) == nil {
+		return notFound()
+	}
+
+	config, ok := s.store.nested["marketplace/resources/config/"+params["id"]]
+	if !ok {
+		return http.StatusOK, map[string]interface{}{}
+	}
+
+	return http.StatusOK, config
+}
+
+// patchConfig merges the patch into the config of a custom resource, which
+// is also exposed as its credential.
+func patchConfig(s *Server, r *http.Request, params map[string]string) (int, interface{}) {
+	id := params["id"]
+	if s.store.get("marketplace/resources", "id", id) == nil {
+		return notFound()
+	}
+
+	in, err := readObject(r)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	key := "marketplace/resources/config/" + id
+	config, _ := s.store.nested[key].(map[string]interface{})
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	for k, v := range in {
+		if v == nil {
+			delete(config, k)
+			continue
+		}
+		config[k] = v
+	}
+	s.store.nested[key] = config
+
+	cred := s.store.get("marketplace/credentials", "resource_id", id)
+	if cred == nil {
+		now := timestamp()
+		cred = object{
+			"type":    "credential",
+			"version": 1,
+			"body": map[string]interface{}{
+				"resource_id": id,
+				"source":      "custom",
+				"created_at":  now,
+			},
+		}
+	}
+	cred.body()["values"] = config
+	cred.body()["updated_at"] = timestamp()
+
+	if _, err := s.store.put("marketplace/credentials", cred); err != nil {
+		return internalError(err)
+	}
+
+	return http.StatusOK, config
+}
+
+// credentials lists credentials by resource, or by the resources of a
+// project.
+func credentials(s *Server, r *http.Request, _ map[string]string) (int, interface{}) {
+	q := r.URL.Query()
+
+	if projects, ok := q["project_id"]; ok {
+		var ids []string
+		for _, res := range s.store.list("marketplace/resources", map[string][]string{"project_id": projects}) {
+			ids = append(ids, res.id())
+		}
+		if len(ids) == 0 {
+			return http.StatusOK, []object{}
+		}
+
+		q.Del("project_id")
+		q["resource_id"] = append(q["resource_id"], strings.Join(ids, ","))
+	}
+
+	return http.StatusOK, s.store.list("marketplace/credentials", q)
+}
+
+func bearer(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,132 @@
+package devserver
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// route is a path template of a service, along with its allowed methods.
+type route struct {
+	template string
+	segments []string
+	methods  map[string]bool
+}
+
+type spec struct {
+	Paths map[string]map[string]interface{} `yaml:"paths"`
+}
+
+var methods = []string{"get", "post", "put", "patch", "delete"}
+
+// loadSpecs reads every OpenAPI spec in dir, using the file name as the name
+// of the service.
+func loadSpecs(dir string) (map[string][]*route, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	services := make(map[string][]*route)
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		s := &spec{}
+		if err := yaml.Unmarshal(b, s); err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		services[name] = routes(s)
+	}
+
+	return services, nil
+}
+
+// routes sorts the paths of the spec so static segments are matched before
+// parameters, such as /invites/accept before /invites/{id}.
+func routes(s *spec) []*route {
+	var out []*route
+	for template, ops := range s.Paths {
+		r := &route{
+			template: template,
+			segments: split(template),
+			methods:  make(map[string]bool),
+		}
+		for _, m := range methods {
+			if _, ok := ops[m]; ok {
+				r.methods[strings.ToUpper(m)] = true
+			}
+		}
+
+		out = append(out, r)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return params(out[i]) < params(out[j]) ||
+			(params(out[i]) == params(out[j]) && out[i].template < out[j].template)
+	})
+
+	return out
+}
+
+func params(r *route) int {
+	n := 0
+	for _, s := range r.segments {
+		if isParam(s) {
+			n++
+		}
+	}
+	return n
+}
+
+// match returns the route matching path along with its path parameters,
+// ignoring trailing slashes.
+func match(routes []*route, path string) (*route, map[string]string) {
+	segments := split(path)
+
+	for _, r := range routes {
+		if len(r.segments) != len(segments) {
+			continue
+		}
+
+		values := make(map[string]string)
+		ok := true
+		for i, s := range r.segments {
+			switch {
+			case isParam(s):
+				values[strings.Trim(s, "{}")] = segments[i]
+			case s != segments[i]:
+				ok = false
+			}
+			if !ok {
+				break
+			}
+		}
+
+		if ok {
+			return r, values
+		}
+	}
+
+	return nil, nil
+}
+
+func split(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
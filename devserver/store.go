@@ -0,0 +1,203 @@
+package devserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+// object is a JSON object, usually an envelope with an id, type, version and
+// body.
+type object map[string]interface{}
+
+// field returns a top level field, or a field of the body.
+func (o object) field(name string) (interface{}, bool) {
+	if v, ok := o[name]; ok {
+		return v, true
+	}
+
+	if body, ok := o["body"].(map[string]interface{}); ok {
+		v, ok := body[name]
+		return v, ok
+	}
+
+	return nil, false
+}
+
+func (o object) body() map[string]interface{} {
+	body, ok := o["body"].(map[string]interface{})
+	if !ok {
+		body = make(map[string]interface{})
+		o["body"] = body
+	}
+
+	return body
+}
+
+func (o object) id() string {
+	id, _ := o["id"].(string)
+	return id
+}
+
+// store holds the objects of every collection in memory. Collections are
+// named after the static segments of their path, such as identity/teams.
+type store struct {
+	items  map[string][]object
+	nested map[string]interface{}
+}
+
+func newStore() *store {
+	return &store{
+		items:  make(map[string][]object),
+		nested: make(map[string]interface{}),
+	}
+}
+
+// list returns the objects of a collection matching every filter. A filter
+// on a field which none of the objects have is ignored.
+func (s *store) list(collection string, filters map[string][]string) []object {
+	out := []object{}
+
+	for _, o := range s.items[collection] {
+		ok := true
+		for name, values := range filters {
+			if !s.known(collection, name) {
+				continue
+			}
+
+			v, found := o.field(name)
+			if !found || !matches(v, values) {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			out = append(out, o)
+		}
+	}
+
+	return out
+}
+
+func (s *store) known(collection, name string) bool {
+	for _, o := range s.items[collection] {
+		if _, ok := o.field(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(v interface{}, values []string) bool {
+	str := fmt.Sprint(v)
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if str == part {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// get finds an object by id, or by a body field named like the path
+// parameter, such as the token of an invite.
+func (s *store) get(collection, param, value string) object {
+	for _, o := range s.items[collection] {
+		if o.id() == value {
+			return o
+		}
+		if v, ok := o.field(param); ok && fmt.Sprint(v) == value {
+			return o
+		}
+	}
+
+	return nil
+}
+
+// put adds an object to a collection, or replaces the object with the same
+// id. Objects without an id are given one matching their type.
+func (s *store) put(collection string, o object) (object, error) {
+	if o.id() == "" {
+		id, err := newID(collection, o)
+		if err != nil {
+			return nil, err
+		}
+		o["id"] = id
+	}
+
+	items := s.items[collection]
+	for i, existing := range items {
+		if existing.id() == o.id() {
+			items[i] = o
+			return o, nil
+		}
+	}
+
+	s.items[collection] = append(items, o)
+	return o, nil
+}
+
+func (s *store) delete(collection, id string) bool {
+	items := s.items[collection]
+	for i, o := range items {
+		if o.id() == id {
+			s.items[collection] = append(items[:i], items[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// newID returns an ID of the type of the object, falling back on the
+// singular name of its collection.
+func newID(collection string, o object) (string, error) {
+	name, _ := o["type"].(string)
+	if name == "" {
+		parts := strings.Split(collection, "/")
+		name = strings.TrimSuffix(parts[len(parts)-1], "s")
+	}
+
+	t, ok := mutableType(name)
+	if !ok {
+		t = idtype.Resource
+	}
+
+	id, err := manifold.NewID(t)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+var typeNames = map[string]idtype.Type{
+	"user":            idtype.User,
+	"team":            idtype.Team,
+	"team_membership": idtype.TeamMembership,
+	"membership":      idtype.TeamMembership,
+	"invite":          idtype.Invite,
+	"token":           idtype.Token,
+	"auth_token":      idtype.Token,
+	"api_token":       idtype.APIToken,
+	"provider":        idtype.Provider,
+	"product":         idtype.Product,
+	"plan":            idtype.Plan,
+	"region":          idtype.Region,
+	"operation":       idtype.Operation,
+	"resource":        idtype.Resource,
+	"credential":      idtype.Credential,
+	"project":         idtype.Project,
+	"profile":         idtype.BillingProfile,
+	"billing_profile": idtype.BillingProfile,
+	"event":           idtype.ActivityEvent,
+}
+
+func mutableType(name string) (idtype.Type, bool) {
+	t, ok := typeNames[name]
+	return t, ok
+}
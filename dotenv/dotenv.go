@@ -0,0 +1,262 @@
+// Package dotenv reads and reconciles `.env` files. Values written by Manifold
+// live inside a marked block, so any other line or comment in the file is
+// preserved as is.
+package dotenv
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// BlockStart marks the beginning of the lines managed by Manifold
+	BlockStart = "# --- manifold:begin (managed by `manifold env sync`, do not edit) ---"
+
+	// BlockEnd marks the end of the lines managed by Manifold
+	BlockEnd = "# --- manifold:end ---"
+
+	// Permissions are the permissions enforced on written files, matching the
+	// ones required for ~/.manifoldrc
+	Permissions = 0600
+)
+
+// File represents a parsed `.env` file.
+type File struct {
+	before  []string
+	after   []string
+	managed map[string]string
+	user    map[string]bool
+}
+
+// Diff describes the changes applied to the managed block by Sync. Keys are
+// sorted alphabetically.
+type Diff struct {
+	Added   []string
+	Changed []string
+	Removed []string
+
+	// Skipped lists keys that are already defined outside the managed block.
+	// They are left untouched.
+	Skipped []string
+}
+
+// Empty returns true if the diff doesn't change the file.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// Parse reads a `.env` file from r.
+func Parse(r io.Reader) (*File, error) {
+	f := &File{
+		managed: make(map[string]string),
+		user:    make(map[string]bool),
+	}
+
+	inBlock := false
+	seenBlock := false
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+
+		switch {
+		case !seenBlock && strings.TrimSpace(line) == BlockStart:
+			inBlock = true
+			seenBlock = true
+		case inBlock && strings.TrimSpace(line) == BlockEnd:
+			inBlock = false
+		case inBlock:
+			if key, value, ok := parseLine(line); ok {
+				f.managed[key] = value
+			}
+		default:
+			if key, _, ok := parseLine(line); ok {
+				f.user[key] = true
+			}
+
+			if seenBlock {
+				f.after = append(f.after, line)
+			} else {
+				f.before = append(f.before, line)
+			}
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Load parses the file at the given path. An empty File is returned if the
+// path does not exist.
+func Load(path string) (*File, error) {
+	fd, err := os.Open(path)
+	switch {
+	case err == nil:
+	case os.IsNotExist(err):
+		return Parse(strings.NewReader(""))
+	default:
+		return nil, err
+	}
+	defer fd.Close()
+
+	return Parse(fd)
+}
+
+// Managed returns a copy of the values found inside the managed block.
+func (f *File) Managed() map[string]string {
+	out := make(map[string]string, len(f.managed))
+	for k, v := range f.managed {
+		out[k] = v
+	}
+	return out
+}
+
+// Sync replaces the managed block with the given values and returns what
+// changed. Keys defined by the user outside of the block are skipped.
+func (f *File) Sync(values map[string]string) Diff {
+	var d Diff
+	managed := make(map[string]string)
+
+	for k, v := range values {
+		if f.user[k] {
+			d.Skipped = append(d.Skipped, k)
+			continue
+		}
+
+		old, ok := f.managed[k]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, k)
+		case old != v:
+			d.Changed = append(d.Changed, k)
+		}
+
+		managed[k] = v
+	}
+
+	for k := range f.managed {
+		if _, ok := managed[k]; !ok {
+			d.Removed = append(d.Removed, k)
+		}
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Changed)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Skipped)
+
+	f.managed = managed
+	return d
+}
+
+// WriteTo writes the file contents to w.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var lines []string
+	lines = append(lines, f.before...)
+
+	if len(f.managed) > 0 {
+		keys := make([]string, 0, len(f.managed))
+		for k := range f.managed {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+
+		lines = append(lines, BlockStart)
+		for _, k := range keys {
+			lines = append(lines, k+"="+quote(f.managed[k]))
+		}
+		lines = append(lines, BlockEnd)
+	}
+
+	lines = append(lines, f.after...)
+
+	var n int64
+	for _, l := range lines {
+		c, err := io.WriteString(w, l+"\n")
+		n += int64(c)
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Write writes the file to the given path, enforcing Permissions even if the
+// file already existed with wider ones.
+func (f *File) Write(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".env-")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteTo(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cErr := tmp.Close(); err == nil {
+		err = cErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), Permissions)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// parseLine returns the key and value of an assignment line, supporting an
+// optional `export` prefix and quoted values.
+func parseLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+
+	line = strings.TrimPrefix(line, "export ")
+
+	idx := strings.Index(line, "=")
+	if idx < 1 {
+		return "", "", false
+	}
+
+	key := strings.TrimSpace(line[:idx])
+	value := strings.TrimSpace(line[idx+1:])
+
+	if len(value) > 1 && value[0] == '"' {
+		if v, err := strconv.Unquote(value); err == nil {
+			return key, v, true
+		}
+	}
+
+	if len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return key, value[1 : len(value)-1], true
+	}
+
+	return key, value, true
+}
+
+// quote wraps values containing whitespace, quotes or special characters in
+// double quotes.
+func quote(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\r\"'#$\\`") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,108 @@
+package dotenv
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const existing = `# local settings
+PORT=3000
+export DEBUG=true
+
+` + BlockStart + `
+DATABASE_URL=postgres://old
+OLD_KEY=gone
+REDIS_URL="redis://localhost:6379"
+` + BlockEnd + `
+# trailing comment
+`
+
+func TestSync(t *testing.T) {
+	f, err := Parse(strings.NewReader(existing))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing: %s", err)
+	}
+
+	d := f.Sync(map[string]string{
+		"DATABASE_URL": "postgres://new",
+		"REDIS_URL":    "redis://localhost:6379",
+		"API_KEY":      "has spaces",
+		"PORT":         "8080",
+	})
+
+	expected := Diff{
+		Added:   []string{"API_KEY"},
+		Changed: []string{"DATABASE_URL"},
+		Removed: []string{"OLD_KEY"},
+		Skipped: []string{"PORT"},
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("Expected diff %+v, got %+v", expected, d)
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := f.WriteTo(buf); err != nil {
+		t.Fatalf("Unexpected error writing: %s", err)
+	}
+
+	out := `# local settings
+PORT=3000
+export DEBUG=true
+
+` + BlockStart + `
+API_KEY="has spaces"
+DATABASE_URL=postgres://new
+REDIS_URL=redis://localhost:6379
+` + BlockEnd + `
+# trailing comment
+`
+	if buf.String() != out {
+		t.Errorf("Expected file to be:\n%s\ngot:\n%s", out, buf.String())
+	}
+
+	f, err = Parse(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing output: %s", err)
+	}
+
+	if f.Managed()["API_KEY"] != "has spaces" {
+		t.Errorf("Expected quoted value to round trip, got %q", f.Managed()["API_KEY"])
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dotenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".env")
+	if err := ioutil.WriteFile(path, []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading: %s", err)
+	}
+
+	f.Sync(map[string]string{"B": "2"})
+	if err := f.Write(path); err != nil {
+		t.Fatalf("Unexpected error writing: %s", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != Permissions {
+		t.Errorf("Expected permissions to be %o, got %o", Permissions, info.Mode().Perm())
+	}
+}
@@ -95,6 +95,32 @@ func NewErrorExitError(message string, err error) error {
 	return cli.NewExitError(message+"\n"+err.Error(), -1)
 }
 
+// NonInteractiveExitCode is the exit status used when a value had to be
+// prompted for while prompts are disabled
+const NonInteractiveExitCode = 3
+
+// NonInteractiveError represents an error where a value is missing and
+// prompts are disabled. Flag names the flag or argument providing the value.
+type NonInteractiveError struct {
+	Prompt string
+	Flag   string
+}
+
+func (e *NonInteractiveError) Error() string {
+	if e.Flag == "" {
+		return fmt.Sprintf("Cannot prompt for %s when running non-interactively", e.Prompt)
+	}
+
+	return fmt.Sprintf("Cannot prompt for %s when running non-interactively, provide it with %s",
+		e.Prompt, e.Flag)
+}
+
+// ExitCode implements cli.ExitCoder, so the process exits with
+// NonInteractiveExitCode
+func (e *NonInteractiveError) ExitCode() int {
+	return NonInteractiveExitCode
+}
+
 type stripeError struct {
 	Message string `json:"message"`
 }
@@ -1,5 +1,7 @@
 module github.com/manifoldco/manifold-cli
 
+go 1.27.1
+
 require (
 	github.com/PuerkitoBio/purell v1.1.0
 	github.com/PuerkitoBio/urlesc v0.0.0-20170810143723-de5bf2ad4578
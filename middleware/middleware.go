@@ -106,7 +106,7 @@ func EnsureTeamPrefs(cliCtx *cli.Context) error {
 
 		teamIdx, _, err := prompts.SelectTeam(teams, "", s.LabelInfo())
 		if err != nil {
-			prompts.HandleSelectError(err, "Could not select team")
+			return prompts.HandleSelectError(err, "Could not select team")
 		}
 
 		if teamIdx == -1 {
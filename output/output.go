@@ -0,0 +1,87 @@
+// Package output renders structured records in a machine readable format so
+// commands can be consumed by scripts instead of scraping tables.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Format represents one of the supported output formats.
+type Format string
+
+const (
+	// Table is the default, human friendly, output format
+	Table Format = "table"
+
+	// JSON renders records as indented JSON
+	JSON Format = "json"
+
+	// YAML renders records as a YAML document
+	YAML Format = "yaml"
+)
+
+// Formats lists every supported output format, in the order they are
+// displayed to the user.
+var Formats = []Format{Table, JSON, YAML}
+
+// Parse returns the Format matching the given value. An empty value is
+// treated as Table.
+func Parse(value string) (Format, error) {
+	if value == "" {
+		return Table, nil
+	}
+
+	for _, f := range Formats {
+		if string(f) == value {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unrecognized output format %q, expected one of %s",
+		value, strings.Join(names(), ", "))
+}
+
+// IsMachine returns true if the format is meant to be consumed by a program
+// and should not include any color, spinner or hint.
+func (f Format) IsMachine() bool {
+	return f == JSON || f == YAML
+}
+
+// Write renders the given value into w using the requested format. Table is
+// not supported, as each command knows how to render its own table.
+func Write(w io.Writer, f Format, v interface{}) error {
+	var b []byte
+	var err error
+
+	switch f {
+	case JSON:
+		b, err = json.MarshalIndent(v, "", "    ")
+		if err == nil {
+			b = append(b, '\n')
+		}
+	case YAML:
+		b, err = yaml.Marshal(v)
+	default:
+		return fmt.Errorf("Cannot write records as %q", f)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+func names() []string {
+	out := make([]string, len(Formats))
+	for i, f := range Formats {
+		out[i] = string(f)
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tcs := []struct {
+		value  string
+		format Format
+		err    bool
+	}{
+		{value: "", format: Table},
+		{value: "table", format: Table},
+		{value: "json", format: JSON},
+		{value: "yaml", format: YAML},
+		{value: "xml", err: true},
+	}
+
+	for _, tc := range tcs {
+		f, err := Parse(tc.value)
+		if tc.err != (err != nil) {
+			t.Errorf("Expected error for %q to be %t, got %v", tc.value, tc.err, err)
+		}
+
+		if f != tc.format {
+			t.Errorf("Expected %q to parse as %q, got %q", tc.value, tc.format, f)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	records := []Token{{ID: "1", Token: "abcd****wxyz", Description: "ci", Role: "read"}}
+
+	tcs := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			format: JSON,
+			expected: `[
+    {
+        "id": "1",
+        "token": "abcd****wxyz",
+        "description": "ci",
+        "role": "read"
+    }
+]
+`,
+		},
+		{
+			format: YAML,
+			expected: `- id: "1"
+  token: abcd****wxyz
+  description: ci
+  role: read
+`,
+		},
+	}
+
+	for _, tc := range tcs {
+		buf := &bytes.Buffer{}
+		if err := Write(buf, tc.format, records); err != nil {
+			t.Fatalf("Unexpected error writing %q: %s", tc.format, err)
+		}
+
+		if buf.String() != tc.expected {
+			t.Errorf("Expected %q output to be:\n%s\ngot:\n%s", tc.format, tc.expected, buf.String())
+		}
+	}
+
+	if err := Write(&bytes.Buffer{}, Table, records); err == nil {
+		t.Error("Expected an error when writing a table")
+	}
+}
@@ -0,0 +1,192 @@
+package output
+
+// Resource is the machine readable representation of a provisioned resource.
+type Resource struct {
+	ID      string `json:"id" yaml:"id"`
+	Name    string `json:"name" yaml:"name"`
+	Title   string `json:"title" yaml:"title"`
+	Owner   string `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	Custom  bool   `json:"custom" yaml:"custom"`
+	Product string `json:"product,omitempty" yaml:"product,omitempty"`
+	Plan    string `json:"plan,omitempty" yaml:"plan,omitempty"`
+	Region  string `json:"region,omitempty" yaml:"region,omitempty"`
+	Status  string `json:"status" yaml:"status"`
+}
+
+// Project is the machine readable representation of a project.
+type Project struct {
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name"`
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Team is the machine readable representation of a team.
+type Team struct {
+	Name    string `json:"name" yaml:"name"`
+	Title   string `json:"title" yaml:"title"`
+	Members int    `json:"members" yaml:"members"`
+}
+
+// Profile is the machine readable representation of an auth profile.
+type Profile struct {
+	Name     string `json:"name" yaml:"name"`
+	Hostname string `json:"hostname" yaml:"hostname"`
+	Team     string `json:"team,omitempty" yaml:"team,omitempty"`
+	LoggedIn bool   `json:"logged_in" yaml:"logged_in"`
+	Active   bool   `json:"active" yaml:"active"`
+}
+
+// Member is the machine readable representation of a team member or a
+// pending invite.
+type Member struct {
+	Name   string `json:"name" yaml:"name"`
+	Email  string `json:"email" yaml:"email"`
+	Role   string `json:"role" yaml:"role"`
+	Status string `json:"status" yaml:"status"`
+}
+
+// Token is the machine readable representation of an API token. The token
+// value itself is always masked.
+type Token struct {
+	ID          string `json:"id" yaml:"id"`
+	Token       string `json:"token" yaml:"token"`
+	Description string `json:"description" yaml:"description"`
+	Role        string `json:"role" yaml:"role"`
+}
+
+// Product is the machine readable representation of a catalog product.
+type Product struct {
+	ID       string `json:"id" yaml:"id"`
+	Name     string `json:"name" yaml:"name"`
+	Title    string `json:"title" yaml:"title"`
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Tagline  string `json:"tagline" yaml:"tagline"`
+}
+
+// Plan is the machine readable representation of a catalog plan. Cost is
+// expressed in cents per month.
+type Plan struct {
+	ID       string            `json:"id" yaml:"id"`
+	Name     string            `json:"name" yaml:"name"`
+	Title    string            `json:"title" yaml:"title"`
+	Product  string            `json:"product,omitempty" yaml:"product,omitempty"`
+	Provider string            `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Cost     int64             `json:"cost" yaml:"cost"`
+	Features map[string]string `json:"features,omitempty" yaml:"features,omitempty"`
+	Regions  []string          `json:"regions,omitempty" yaml:"regions,omitempty"`
+}
+
+// Event is the machine readable representation of an activity event.
+type Event struct {
+	ID        string `json:"id" yaml:"id"`
+	Type      string `json:"type" yaml:"type"`
+	Actor     string `json:"actor,omitempty" yaml:"actor,omitempty"`
+	ActorID   string `json:"actor_id" yaml:"actor_id"`
+	Scope     string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	ScopeID   string `json:"scope_id" yaml:"scope_id"`
+	Source    string `json:"source,omitempty" yaml:"source,omitempty"`
+	IP        string `json:"ip,omitempty" yaml:"ip,omitempty"`
+	CreatedAt string `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	Resource  string `json:"resource,omitempty" yaml:"resource,omitempty"`
+	Project   string `json:"project,omitempty" yaml:"project,omitempty"`
+	Product   string `json:"product,omitempty" yaml:"product,omitempty"`
+	Plan      string `json:"plan,omitempty" yaml:"plan,omitempty"`
+	OldPlan   string `json:"old_plan,omitempty" yaml:"old_plan,omitempty"`
+}
+
+// Cost is the machine readable representation of the monthly cost of a
+// resource, in cents. Unlisted is set for plans no longer in the catalog.
+type Cost struct {
+	Owner    string `json:"owner" yaml:"owner"`
+	Project  string `json:"project,omitempty" yaml:"project,omitempty"`
+	Resource string `json:"resource" yaml:"resource"`
+	Product  string `json:"product" yaml:"product"`
+	Plan     string `json:"plan" yaml:"plan"`
+	Cost     int64  `json:"cost" yaml:"cost"`
+	Unlisted bool   `json:"unlisted" yaml:"unlisted"`
+}
+
+// PlanComparison is the machine readable representation of plans compared
+// side by side. Every row holds a value per plan, in the order of Plans.
+type PlanComparison struct {
+	Product string          `json:"product" yaml:"product"`
+	Plans   []string        `json:"plans" yaml:"plans"`
+	Costs   []int64         `json:"costs" yaml:"costs"`
+	Rows    []ComparisonRow `json:"rows" yaml:"rows"`
+}
+
+// ComparisonRow is a single aspect of the compared plans, such as their cost
+// or a feature. Differs is set when the plans don't share the same value.
+type ComparisonRow struct {
+	Name    string   `json:"name" yaml:"name"`
+	Title   string   `json:"title" yaml:"title"`
+	Values  []string `json:"values" yaml:"values"`
+	Differs bool     `json:"differs" yaml:"differs"`
+}
+
+// Plugin is the machine readable representation of an installed plugin.
+// Status is verified, modified or unmanaged when it has no manifest.
+type Plugin struct {
+	Name     string `json:"name" yaml:"name"`
+	Command  string `json:"command" yaml:"command"`
+	Source   string `json:"source,omitempty" yaml:"source,omitempty"`
+	Version  string `json:"version,omitempty" yaml:"version,omitempty"`
+	Commit   string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Checksum string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+	Status   string `json:"status" yaml:"status"`
+}
+
+// Leak is the machine readable representation of a credential value found in
+// a file. The value itself is never included.
+type Leak struct {
+	Path     string `json:"path" yaml:"path"`
+	Line     int    `json:"line" yaml:"line"`
+	Commit   string `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Resource string `json:"resource" yaml:"resource"`
+	Key      string `json:"key" yaml:"key"`
+}
+
+// Credit is the machine readable representation of a coupon or credit applied
+// to an account, with its balance at the start of every billing period.
+type Credit struct {
+	ID          string         `json:"id" yaml:"id"`
+	CouponID    string         `json:"coupon_id,omitempty" yaml:"coupon_id,omitempty"`
+	Code        string         `json:"code,omitempty" yaml:"code,omitempty"`
+	Reason      string         `json:"reason,omitempty" yaml:"reason,omitempty"`
+	AppliedAt   string         `json:"applied_at" yaml:"applied_at"`
+	Amount      int64          `json:"amount" yaml:"amount"`
+	Balance     int64          `json:"balance" yaml:"balance"`
+	ExhaustedIn string         `json:"exhausted_in,omitempty" yaml:"exhausted_in,omitempty"`
+	Periods     []CreditPeriod `json:"periods" yaml:"periods"`
+}
+
+// CreditPeriod is a billing period of a Credit. Used is only set once the
+// period closed. ExpiresAt is when the remaining balance rolls over, or is
+// lost when nothing remains.
+type CreditPeriod struct {
+	Period    string `json:"period" yaml:"period"`
+	Opening   int64  `json:"opening" yaml:"opening"`
+	Used      *int64 `json:"used,omitempty" yaml:"used,omitempty"`
+	ExpiresAt string `json:"expires_at" yaml:"expires_at"`
+}
+
+// Verification is the machine readable result of verifying the subscription
+// event log. BrokenEvent and Reason describe the first event failing it.
+type Verification struct {
+	Events      int    `json:"events" yaml:"events"`
+	Verified    bool   `json:"verified" yaml:"verified"`
+	BrokenEvent string `json:"broken_event,omitempty" yaml:"broken_event,omitempty"`
+	Reason      string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// OAuthClient is the machine readable representation of an OAuth client of a
+// product. Secret is only set when the client is created.
+type OAuthClient struct {
+	ID          string `json:"id" yaml:"id"`
+	Product     string `json:"product" yaml:"product"`
+	Description string `json:"description" yaml:"description"`
+	Secret      string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	CreatedAt   string `json:"created_at" yaml:"created_at"`
+}
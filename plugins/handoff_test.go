@@ -0,0 +1,74 @@
+//go:build !windows
+// +build !windows
+
+package plugins
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/manifoldco/manifold-cli/plugins/sdk"
+)
+
+func TestHandoff(t *testing.T) {
+	home, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(home)
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	name := "manifold-cli-example"
+	binPath, err := Executable(name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := os.MkdirAll(path.Dir(binPath), 0700); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := os.MkdirAll(path.Join(home, directory, name, ".git"), 0700); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	out := path.Join(home, "context.json")
+	script := fmt.Sprintf("#!/bin/sh\neval \"cat <&$%s\" > %s\n", sdk.EnvContextFD, out)
+	if err := ioutil.WriteFile(binPath, []byte(script), 0700); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	sum, err := Checksum(name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := WriteManifest(&Manifest{Name: name, Checksum: sum}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	c := &sdk.Context{
+		Hostname: "manifold.co",
+		Token:    "secret",
+		Config:   map[string]interface{}{"region": "us-east-1"},
+	}
+	ok, err := Run("example", c)
+	if !ok || err != nil {
+		t.Fatalf("Expected the plugin to run, got %v", err)
+	}
+
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	handed, err := sdk.Parse(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if handed.Plugin != name || handed.Token != "secret" || handed.Config["region"] != "us-east-1" {
+		t.Errorf("Unexpected context %+v", handed)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+// +build !windows
+
+package plugins
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/manifoldco/manifold-cli/plugins/sdk"
+)
+
+// handoff passes the context through a pipe inherited as the first extra
+// file descriptor, keeping the token out of the plugin's environment. The
+// returned func must be called once the process started.
+func handoff(proc *exec.Cmd, b []byte) (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	proc.ExtraFiles = append(proc.ExtraFiles, r)
+	fd := 2 + len(proc.ExtraFiles)
+	proc.Env = append(proc.Env, sdk.EnvContextFD+"="+strconv.Itoa(fd))
+
+	return func() {
+		r.Close()
+
+		// Plugins which never read the context close the pipe when they exit
+		go func() {
+			w.Write(b)
+			w.Close()
+		}()
+	}, nil
+}
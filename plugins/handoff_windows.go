@@ -0,0 +1,14 @@
+package plugins
+
+import (
+	"os/exec"
+
+	"github.com/manifoldco/manifold-cli/plugins/sdk"
+)
+
+// handoff passes the context through the environment, as file descriptors
+// can't be inherited on Windows.
+func handoff(proc *exec.Cmd, b []byte) (func(), error) {
+	proc.Env = append(proc.Env, sdk.EnvContext+"="+string(b))
+	return func() {}, nil
+}
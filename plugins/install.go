@@ -0,0 +1,161 @@
+package plugins
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotInstalled is returned when managing a plugin which isn't installed.
+var ErrNotInstalled = errors.New("Plugin not installed")
+
+// ErrAlreadyInstalled is returned when installing a plugin twice.
+var ErrAlreadyInstalled = errors.New("Plugin already installed")
+
+// Install clones the plugin repository, checks out the version when one is
+// given and records the plugin's manifest.
+func Install(repository, version string) (*Manifest, error) {
+	pluginsDir, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	name := DeriveName(repository)
+	dir := path.Join(pluginsDir, name)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return nil, ErrAlreadyInstalled
+	}
+
+	source := NormalizeURL(repository)
+	if _, err := git("", "clone", "--quiet", source, dir); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Name: name, Source: source, Version: version}
+	if err := checkout(m); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Update fetches the plugin repository and checks out its pinned version, or
+// the latest commit of the default branch when it isn't pinned. The version
+// replaces the pin when given; latest removes it.
+func Update(name, version string, latest bool) (*Manifest, error) {
+	dir, err := installed(name)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := ReadManifest(name)
+	if err == ErrNoManifest {
+		source, err := git(dir, "config", "--get", "remote.origin.url")
+		if err != nil {
+			return nil, err
+		}
+		m = &Manifest{Name: name, Source: source}
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case latest:
+		m.Version = ""
+	case version != "":
+		m.Version = version
+	}
+
+	if _, err := git(dir, "fetch", "--quiet", "--tags", "origin"); err != nil {
+		return nil, err
+	}
+
+	return m, checkout(m)
+}
+
+// Remove deletes an installed plugin along with its configuration.
+func Remove(name string) error {
+	dir, err := installed(name)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
+
+// checkout moves the plugin's repository to its version, then records the
+// commit and binary checksum in its manifest.
+func checkout(m *Manifest) error {
+	dir, err := installed(m.Name)
+	if err != nil {
+		return err
+	}
+
+	// Remote branches take precedence, so a version naming a branch follows it
+	// rather than the local copy made when the plugin was cloned
+	ref := "origin/HEAD"
+	if m.Version != "" {
+		ref = m.Version
+		if _, err := git(dir, "rev-parse", "--verify", "--quiet", "origin/"+ref+"^{commit}"); err == nil {
+			ref = "origin/" + ref
+		}
+	}
+
+	if _, err := git(dir, "checkout", "--quiet", "--detach", ref); err != nil {
+		return fmt.Errorf("Could not check out version %q: %s", m.Version, err)
+	}
+
+	m.Commit, err = git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	m.Checksum, err = Checksum(m.Name)
+	if err != nil {
+		return fmt.Errorf("No binary found for %s_%s: %s", goos, goarch, err)
+	}
+
+	m.UpdatedAt = time.Now().UTC()
+	return WriteManifest(m)
+}
+
+func installed(name string) (string, error) {
+	pluginsDir, err := Path()
+	if err != nil {
+		return "", err
+	}
+
+	dir := path.Join(pluginsDir, name)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return "", ErrNotInstalled
+	}
+
+	return dir, nil
+}
+
+// git runs a git command within dir, returning its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,118 @@
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+)
+
+const manifestFile = ".manifest.json"
+
+// ErrNoManifest is returned for plugins installed before manifests were
+// recorded. Updating the plugin records one.
+var ErrNoManifest = errors.New("Plugin has no manifest, run `manifold plugins update` to record one")
+
+// ErrChecksumMismatch is returned when a plugin binary changed since it was
+// installed or updated.
+var ErrChecksumMismatch = errors.New("Plugin binary does not match its recorded checksum")
+
+// Manifest records where a plugin was installed from, and the checksum of the
+// binary which was installed.
+type Manifest struct {
+	Name      string    `json:"name"`
+	Source    string    `json:"source"`
+	Version   string    `json:"version,omitempty"`
+	Commit    string    `json:"commit"`
+	Checksum  string    `json:"checksum"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Pinned returns whether the plugin is held at a version instead of following
+// its repository's default branch.
+func (m *Manifest) Pinned() bool {
+	return m.Version != ""
+}
+
+// ReadManifest returns the manifest of an installed plugin.
+func ReadManifest(name string) (*Manifest, error) {
+	pluginsDir, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(path.Join(pluginsDir, name, manifestFile))
+	if os.IsNotExist(err) {
+		return nil, ErrNoManifest
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// WriteManifest records the manifest of an installed plugin.
+func WriteManifest(m *Manifest) error {
+	pluginsDir, err := Path()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path.Join(pluginsDir, m.Name, manifestFile), b, 0600)
+}
+
+// Checksum returns the hex encoded SHA-256 checksum of the plugin's binary.
+func Checksum(name string) (string, error) {
+	binPath, err := Executable(name)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(binPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Verify checks the plugin's binary against the checksum in its manifest.
+func Verify(name string) error {
+	m, err := ReadManifest(name)
+	if err != nil {
+		return err
+	}
+
+	sum, err := Checksum(name)
+	if err != nil {
+		return err
+	}
+
+	if sum != m.Checksum {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
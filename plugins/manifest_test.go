@@ -0,0 +1,71 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	home, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(home)
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	name := "manifold-cli-example"
+	binPath, err := Executable(name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := os.MkdirAll(path.Dir(binPath), 0700); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := os.MkdirAll(path.Join(home, directory, name, ".git"), 0700); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := os.MkdirAll(path.Join(home, directory, "bin"), 0700); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := ioutil.WriteFile(binPath, []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	plugs, err := List()
+	if err != nil || len(plugs) != 1 || plugs[0] != name {
+		t.Fatalf("Expected only the plugin to be listed, got %v (%v)", plugs, err)
+	}
+
+	if err := Verify(name); err != ErrNoManifest {
+		t.Errorf("Expected ErrNoManifest, got %v", err)
+	}
+	if _, err := Run("example", nil); err != ErrNoManifest {
+		t.Errorf("Expected Run to refuse a plugin without manifest, got %v", err)
+	}
+
+	sum, err := Checksum(name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := WriteManifest(&Manifest{Name: name, Checksum: sum}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if err := Verify(name); err != nil {
+		t.Errorf("Expected the binary to be verified, got %s", err)
+	}
+
+	if err := ioutil.WriteFile(binPath, []byte("#!/bin/sh\necho tampered\n"), 0700); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := Verify(name); err != ErrChecksumMismatch {
+		t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+	}
+	if _, err := Run("example", nil); err != ErrChecksumMismatch {
+		t.Errorf("Expected Run to refuse the binary, got %v", err)
+	}
+}
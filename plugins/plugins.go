@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/manifoldco/manifold-cli/config"
+	"github.com/manifoldco/manifold-cli/plugins/sdk"
 )
 
 const (
@@ -68,7 +70,12 @@ func List() ([]string, error) {
 	if err != nil {
 		return plugs, ErrFailedToRead
 	}
+	// Only repositories are plugins, the directory also holds the manifold
+	// binary when it was installed through the install script
 	for _, f := range files {
+		if _, err := os.Stat(path.Join(pluginsDir, f.Name(), ".git")); err != nil {
+			continue
+		}
 		plugs = append(plugs, f.Name())
 	}
 
@@ -133,40 +140,119 @@ func Help(cmd string) error {
 	return errors.New("Plugin not found")
 }
 
-// Run executes the requested plugin command and redirects stdout
-func Run(cmd string) (bool, error) {
-	// List installed plugins
+// Lookup returns the name of the installed plugin providing the command, or
+// an empty string when there is none.
+func Lookup(cmd string) (string, error) {
 	plugs, err := List()
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
 	for _, p := range plugs {
-		if cmd == strings.Replace(p, pluginPrefix, "", 1) {
-			// Identify the executable path for the plugin
-			binPath, err := Executable(p)
-			if err != nil {
-				return false, err
-			}
+		if cmd == Shortname(p) {
+			return p, nil
+		}
+	}
 
-			// Construct execution of the plugin binary
-			var pluginArgs []string
-			pluginArgs = append(pluginArgs, os.Args[2:]...)
-			proc := exec.Command(binPath, pluginArgs...)
-			proc.Stdout = os.Stdout
-			proc.Stderr = os.Stderr
+	return "", nil
+}
 
-			// Execute
-			err = proc.Run()
-			if err != nil {
-				return false, err
-			}
-			return true, nil
+// Run executes the requested plugin command and redirects stdout. The context
+// is handed off to the plugin along with its configuration, when given.
+func Run(cmd string, c *sdk.Context) (bool, error) {
+	p, err := Lookup(cmd)
+	if err != nil || p == "" {
+		// Plugin not found, no error
+		return false, err
+	}
+
+	// Identify the executable path for the plugin
+	binPath, err := Executable(p)
+	if err != nil {
+		return false, err
+	}
+
+	// Refuse binaries which changed since they were installed, or which have
+	// no manifest to verify them against
+	if err := Verify(p); err != nil {
+		return false, err
+	}
+
+	// Construct execution of the plugin binary
+	var pluginArgs []string
+	pluginArgs = append(pluginArgs, os.Args[2:]...)
+	proc := exec.Command(binPath, pluginArgs...)
+	proc.Stdout = os.Stdout
+	proc.Stderr = os.Stderr
+
+	started := func() {}
+	if c != nil {
+		started, err = handoffContext(proc, p, c)
+		if err != nil {
+			return false, err
 		}
 	}
 
-	// Plugin not found, no error
-	return false, nil
+	// Execute
+	if err := proc.Start(); err != nil {
+		return false, err
+	}
+	started()
+
+	if err := proc.Wait(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// handoffContext completes the context with the plugin's configuration and
+// hands it off to the process.
+func handoffContext(proc *exec.Cmd, name string, c *sdk.Context) (func(), error) {
+	c.Version = sdk.Version
+	c.Plugin = name
+
+	if c.Config == nil {
+		err := Config(name, &c.Config)
+		if err != nil && err != config.ErrPluginNotFound {
+			return nil, err
+		}
+		for k, v := range c.Config {
+			c.Config[k] = jsonValue(v)
+		}
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Contexts inherited from a plugin running manifold are replaced
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, sdk.EnvContextFD+"=") && !strings.HasPrefix(e, sdk.EnvContext+"=") {
+			proc.Env = append(proc.Env, e)
+		}
+	}
+
+	return handoff(proc, b)
+}
+
+// jsonValue converts the maps decoded from YAML, which are keyed by
+// interface{}, so they can be encoded as JSON.
+func jsonValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprint(k)] = jsonValue(v)
+		}
+		return m
+	case []interface{}:
+		for i, v := range t {
+			t[i] = jsonValue(v)
+		}
+	}
+
+	return v
 }
 
 // Config returns the key value configuration found in the plugin directory
@@ -0,0 +1,136 @@
+// Package sdk reads the context the manifold CLI hands to the plugins it
+// runs, so plugins don't have to load its configuration themselves.
+//
+// The context is JSON written to the file descriptor named by EnvContextFD,
+// or, where file descriptors can't be inherited, held in EnvContext.
+//
+//	ctx, err := sdk.Load()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	var conf struct{ Region string }
+//	err = ctx.Decode(&conf)
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+)
+
+// Version is the version of the context written by the CLI. Fields are only
+// added within a version.
+const Version = 1
+
+const (
+	// EnvContextFD names the file descriptor the context is read from.
+	EnvContextFD = "MANIFOLD_PLUGIN_CONTEXT_FD"
+
+	// EnvContext holds the context itself when no descriptor is given.
+	EnvContext = "MANIFOLD_PLUGIN_CONTEXT"
+)
+
+// ErrNoContext is returned when the plugin wasn't run by the manifold CLI,
+// or by a version which doesn't hand off a context.
+var ErrNoContext = errors.New("No context was handed off, run the plugin through manifold")
+
+// Context is the state of the CLI when it ran the plugin.
+type Context struct {
+	Version    int    `json:"version"`
+	CLIVersion string `json:"cli_version"`
+	Plugin     string `json:"plugin"`
+
+	// Profile, Hostname and Scheme locate the Manifold API, and Token
+	// authenticates against it. Token is empty when logged out.
+	Profile  string `json:"profile"`
+	Hostname string `json:"hostname"`
+	Scheme   string `json:"scheme"`
+	Token    string `json:"token,omitempty"`
+
+	// Team is nil when acting as the user rather than a team, or when the
+	// team of the directory's .manifold.yml couldn't be resolved
+	Team *Team `json:"team,omitempty"`
+
+	// Project is the project of the directory's .manifold.yml
+	Project string `json:"project,omitempty"`
+
+	// Config is the plugin's block of the directory's .manifold.yml
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+// Team identifies the team the CLI acts as.
+type Team struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Title string `json:"title,omitempty"`
+}
+
+// Load reads the context handed off by the CLI.
+func Load() (*Context, error) {
+	var b []byte
+	if fd := os.Getenv(EnvContextFD); fd != "" {
+		n, err := strconv.Atoi(fd)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s: %s", EnvContextFD, fd)
+		}
+
+		f := os.NewFile(uintptr(n), "context")
+		defer f.Close()
+
+		b, err = ioutil.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+	} else if v := os.Getenv(EnvContext); v != "" {
+		b = []byte(v)
+	} else {
+		return nil, ErrNoContext
+	}
+
+	return Parse(b)
+}
+
+// Parse decodes a context, refusing versions newer than this package.
+func Parse(b []byte) (*Context, error) {
+	c := &Context{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("Invalid context: %s", err)
+	}
+
+	if c.Version < 1 || c.Version > Version {
+		return nil, fmt.Errorf("Unsupported context version %d, expected up to %d", c.Version,
+			Version)
+	}
+
+	return c, nil
+}
+
+// Decode stores the plugin's configuration in v, as with json.Unmarshal.
+func (c *Context) Decode(v interface{}) error {
+	b, err := json.Marshal(c.Config)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// URL returns the base URL of a Manifold service, such as marketplace, the
+// way the CLI derives it.
+func (c *Context) URL(service string) string {
+	host, _, err := net.SplitHostPort(c.Hostname)
+	if err != nil {
+		host = c.Hostname
+	}
+
+	if host == "localhost" || net.ParseIP(host) != nil {
+		return fmt.Sprintf("%s://%s/%s/v1", c.Scheme, c.Hostname, service)
+	}
+
+	return fmt.Sprintf("%s://api.%s.%s/v1", c.Scheme, service, c.Hostname)
+}
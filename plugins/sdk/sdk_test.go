@@ -0,0 +1,52 @@
+package sdk
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	defer os.Unsetenv(EnvContext)
+
+	os.Setenv(EnvContext, `{"version":1,"plugin":"manifold-cli-example",
+		"hostname":"manifold.co","scheme":"https","team":{"id":"1","label":"ops"},
+		"config":{"region":"us-east-1","retries":3}}`)
+
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if c.Team == nil || c.Team.Label != "ops" {
+		t.Errorf("Expected the team to be loaded, got %+v", c.Team)
+	}
+
+	var conf struct {
+		Region  string `json:"region"`
+		Retries int    `json:"retries"`
+	}
+	if err := c.Decode(&conf); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if conf.Region != "us-east-1" || conf.Retries != 3 {
+		t.Errorf("Expected the plugin config to be decoded, got %+v", conf)
+	}
+
+	if u := c.URL("marketplace"); u != "https://api.marketplace.manifold.co/v1" {
+		t.Errorf("Unexpected URL %s", u)
+	}
+
+	c.Hostname = "localhost:8080"
+	if u := c.URL("marketplace"); u != "https://localhost:8080/marketplace/v1" {
+		t.Errorf("Unexpected local URL %s", u)
+	}
+
+	os.Setenv(EnvContext, `{"version":2}`)
+	if _, err := Load(); err == nil {
+		t.Error("Expected newer versions to be refused")
+	}
+
+	os.Unsetenv(EnvContext)
+	if _, err := Load(); err != ErrNoContext {
+		t.Errorf("Expected ErrNoContext, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package prompts
+
+import (
+	"os"
+
+	"github.com/chzyer/readline"
+
+	"github.com/manifoldco/manifold-cli/errs"
+)
+
+// NonInteractive disables prompts. Any prompt returns an
+// *errs.NonInteractiveError instead of waiting for input. It defaults to true
+// when stdin is not a terminal.
+var NonInteractive = false
+
+func init() {
+	NonInteractive = !readline.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ensureInteractive returns an error naming the flag which provides the
+// value, when prompts are disabled.
+func ensureInteractive(prompt, flag string) error {
+	if !NonInteractive {
+		return nil
+	}
+
+	return &errs.NonInteractiveError{Prompt: prompt, Flag: flag}
+}
@@ -60,6 +60,10 @@ func Name(field, defaultValue string, autoSelect, allowEmpty bool) (string, erro
 		return defaultValue, err
 	}
 
+	if err := ensureInteractive("the "+strings.ToLower(field)+" name", "the name argument"); err != nil {
+		return "", err
+	}
+
 	p := promptui.Prompt{
 		Label:    label,
 		Default:  defaultValue,
@@ -76,6 +80,10 @@ func ResourceName(defaultValue string, autoSelect bool) (string, error) {
 
 // TokenDescription prompts the user to enter a token description
 func TokenDescription() (string, error) {
+	if err := ensureInteractive("the token description", "--description"); err != nil {
+		return "", err
+	}
+
 	p := promptui.Prompt{
 		Label:   "Token Description",
 		Default: "",
@@ -83,6 +91,19 @@ func TokenDescription() (string, error) {
 	return p.Run()
 }
 
+// OAuthClientDescription prompts the user to describe an OAuth client
+func OAuthClientDescription() (string, error) {
+	if err := ensureInteractive("the OAuth client description", "--description"); err != nil {
+		return "", err
+	}
+
+	p := promptui.Prompt{
+		Label:   "OAuth Client Description",
+		Default: "",
+	}
+	return p.Run()
+}
+
 // ProjectDescription prompts the user to enter a project description
 func ProjectDescription(defaultValue string, autoSelect bool) (string, error) {
 	label := "Project Description"
@@ -92,6 +113,10 @@ func ProjectDescription(defaultValue string, autoSelect bool) (string, error) {
 		return defaultValue, nil
 	}
 
+	if err := ensureInteractive("the project description", "--description"); err != nil {
+		return "", err
+	}
+
 	p := promptui.Prompt{
 		Label:   label,
 		Default: defaultValue,
@@ -103,6 +128,10 @@ func ProjectDescription(defaultValue string, autoSelect bool) (string, error) {
 // Email prompts the user to provide an email *or* accepted the default
 // email value
 func Email(defaultValue string) (string, error) {
+	if err := ensureInteractive("an email address", "the email argument"); err != nil {
+		return "", err
+	}
+
 	p := promptui.Prompt{
 		Label: "Email",
 		Validate: func(input string) error {
@@ -124,6 +153,10 @@ func Email(defaultValue string) (string, error) {
 
 // FullName prompts the user to input a person's name
 func FullName(defaultValue string) (string, error) {
+	if err := ensureInteractive("a name", "the name argument"); err != nil {
+		return "", err
+	}
+
 	p := promptui.Prompt{
 		Label: "Name",
 		Validate: func(input string) error {
@@ -142,6 +175,10 @@ func FullName(defaultValue string) (string, error) {
 
 // CouponCode prompts the user to input an alphanumeric coupon code.
 func CouponCode() (string, error) {
+	if err := ensureInteractive("a coupon code", "the code argument"); err != nil {
+		return "", err
+	}
+
 	p := promptui.Prompt{
 		Label: "Code",
 		Validate: func(input string) error {
@@ -157,6 +194,10 @@ func CouponCode() (string, error) {
 
 // EmailVerificationCode prompts the user to input a person's name
 func EmailVerificationCode(defaultValue string) (string, error) {
+	if err := ensureInteractive("the e-mail verification code", "the code argument"); err != nil {
+		return "", err
+	}
+
 	p := promptui.Prompt{
 		Label: "E-mail Verification Code",
 		Validate: func(input string) error {
@@ -178,6 +219,10 @@ const PasswordMask = '●'
 
 // Password prompts the user to input a password value
 func Password() (string, error) {
+	if err := ensureInteractive("a password", "MANIFOLD_PASS"); err != nil {
+		return "", err
+	}
+
 	prompt := promptui.Prompt{
 		Label: "Password",
 		Mask:  PasswordMask,
@@ -193,8 +238,13 @@ func Password() (string, error) {
 	return prompt.Run()
 }
 
-// Confirm is a confirmation prompt
-func Confirm(msg string) (string, error) {
+// Confirm is a confirmation prompt. Flag names the flag skipping it, if the
+// command has one.
+func Confirm(msg, flag string) (string, error) {
+	if err := ensureInteractive("a confirmation", flag); err != nil {
+		return "", err
+	}
+
 	p := promptui.Prompt{
 		Label:     msg,
 		IsConfirm: true,
@@ -210,6 +260,10 @@ func HandleSelectError(err error, generic string) error {
 		return err
 	}
 
+	if _, ok := err.(*errs.NonInteractiveError); ok {
+		return err
+	}
+
 	return errs.NewErrorExitError(generic, err)
 }
 
@@ -239,6 +293,10 @@ func isCVV(raw string) error {
 
 // CreditCard handles receiving and tokenizing payment information
 func CreditCard() (*stripe.Token, error) {
+	if err := ensureInteractive("credit card details", ""); err != nil {
+		return nil, err
+	}
+
 	rCrd, err := (&promptui.Prompt{
 		Label:    "💳  Card Number",
 		Validate: isCard,
@@ -327,6 +385,10 @@ func InvitationCode(defaultValue string) (string, error) {
 		return defaultValue, err
 	}
 
+	if err := ensureInteractive("an invitation token", "the token argument"); err != nil {
+		return "", err
+	}
+
 	p := promptui.Prompt{
 		Label:    label,
 		Validate: validate,
@@ -39,6 +39,10 @@ func SelectProduct(list []*cModels.Product, name string) (int, string, error) {
 		return idx, name, nil
 	}
 
+	if err := ensureInteractive("a product", "--product"); err != nil {
+		return 0, "", err
+	}
+
 	prompt := promptui.Select{
 		Label:     "Select Product",
 		Items:     products,
@@ -76,6 +80,10 @@ func SelectPlan(list []*cModels.Plan, name string) (int, string, error) {
 		return idx, name, nil
 	}
 
+	if err := ensureInteractive("a plan", "--plan"); err != nil {
+		return 0, "", err
+	}
+
 	prompt := promptui.Select{
 		Label:     "Select Plan",
 		Items:     plans,
@@ -115,6 +123,10 @@ func SelectResource(list []*mModels.Resource, projects []*mModels.Project,
 		return idx, name, nil
 	}
 
+	if err := ensureInteractive("a resource", "the resource name argument"); err != nil {
+		return 0, "", err
+	}
+
 	prompt := promptui.Select{
 		Label:     "Select Resource",
 		Items:     resources,
@@ -126,6 +138,10 @@ func SelectResource(list []*mModels.Resource, projects []*mModels.Project,
 
 // SelectRole prompts the user to select a role from the given list.
 func SelectRole(roles []string) (string, error) {
+	if err := ensureInteractive("a role", "--role"); err != nil {
+		return "", err
+	}
+
 	prompt := promptui.Select{
 		Label: "Select Role",
 		Items: roles,
@@ -147,6 +163,10 @@ func SelectRegion(list []*cModels.Region) (int, string, error) {
 		return 0, string(regions[0].Name), nil
 	}
 
+	if err := ensureInteractive("a region", "--region"); err != nil {
+		return 0, "", err
+	}
+
 	prompt := promptui.Select{
 		Label:     "Select Region",
 		Items:     regions,
@@ -187,6 +207,10 @@ func SelectProject(list []*mModels.Project, name string, emptyOption, showResult
 		return idx, name, nil
 	}
 
+	if err := ensureInteractive("a project", "--project"); err != nil {
+		return 0, "", err
+	}
+
 	if emptyOption {
 		projects = append([]templates.Project{{Name: "No Project"}}, projects...)
 	}
@@ -249,6 +273,10 @@ func selectTeam(list []*iModels.Team, label, name string, userTuple *[]string) (
 		return idx, name, nil
 	}
 
+	if err := ensureInteractive("a team", "--team"); err != nil {
+		return 0, "", err
+	}
+
 	if userTuple != nil {
 		u := *userTuple
 		user := templates.Team{
@@ -278,6 +306,10 @@ func selectTeam(list []*iModels.Team, label, name string, userTuple *[]string) (
 func SelectCategory(list map[string][]*cModels.Product) (string, error) {
 	categories := templates.Categories(list)
 
+	if err := ensureInteractive("a category", ""); err != nil {
+		return "", err
+	}
+
 	prompt := promptui.Select{
 		Label:     "Select Category",
 		Items:     categories,
@@ -297,6 +329,10 @@ func SelectCategory(list map[string][]*cModels.Product) (string, error) {
 func SelectProvider(list []*cModels.Provider) (*cModels.Provider, error) {
 	providers := templates.Providers(list)
 
+	if err := ensureInteractive("a provider", "--provider"); err != nil {
+		return nil, err
+	}
+
 	label := templates.Provider{Name: "All Providers"}
 	providers = append([]templates.Provider{label}, providers...)
 
@@ -320,6 +356,10 @@ func SelectProvider(list []*cModels.Provider) (*cModels.Provider, error) {
 
 // SelectAPIToken prompts the user to choose from a list of tokens
 func SelectAPIToken(tokens []*iModels.APIToken) (*iModels.APIToken, error) {
+	if err := ensureInteractive("an API token", ""); err != nil {
+		return nil, err
+	}
+
 	var labels []string
 	for _, t := range tokens {
 		val := fmt.Sprintf("%s****%s", *t.Body.FirstFour, *t.Body.LastFour)
@@ -339,8 +379,28 @@ func SelectAPIToken(tokens []*iModels.APIToken) (*iModels.APIToken, error) {
 	return tokens[idx], nil
 }
 
+// SelectOAuthClient prompts the user to choose from a list of OAuth clients,
+// described by labels, returning the index of the selected one
+func SelectOAuthClient(labels []string) (int, error) {
+	if err := ensureInteractive("an OAuth client", ""); err != nil {
+		return 0, err
+	}
+
+	prompt := promptui.Select{
+		Label: "Select OAuth client",
+		Items: labels,
+	}
+
+	idx, _, err := prompt.Run()
+	return idx, err
+}
+
 // SelectCredential prompts the user to choose from a list of credentials
 func SelectCredential(creds []*mModels.Credential) (*mModels.Credential, string, error) {
+	if err := ensureInteractive("a credential", ""); err != nil {
+		return nil, "", err
+	}
+
 	var labels []string
 	var keyNames []string
 	var items []*mModels.Credential
@@ -375,6 +435,10 @@ func SelectCredential(creds []*mModels.Credential) (*mModels.Credential, string,
 // SelectBillingProfileAction allows the user to define their next action
 // when encountering a paid plan without stored payment information
 func SelectBillingProfileAction() (string, error) {
+	if err := ensureInteractive("a billing action", ""); err != nil {
+		return "", err
+	}
+
 	prompt := promptui.Select{
 		Label: "Select Action",
 		Items: []string{
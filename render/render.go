@@ -0,0 +1,119 @@
+// Package render executes text/template files against a set of credentials,
+// so they can be written into configuration files which aren't read from the
+// environment.
+package render
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Data is the value exposed to templates.
+type Data struct {
+	// Credentials holds the flattened credentials of every resource
+	Credentials map[string]string
+
+	// Resources holds resource metadata, indexed by resource name
+	Resources map[string]Resource
+
+	// Project is the project the credentials were fetched for, if any
+	Project *Project
+}
+
+// Resource is the metadata of a resource exposed to templates.
+type Resource struct {
+	ID          string
+	Name        string
+	Title       string
+	Credentials map[string]string
+}
+
+// Project is the metadata of a project exposed to templates.
+type Project struct {
+	ID    string
+	Name  string
+	Title string
+}
+
+// Execute parses the template text and renders it into w. Referencing a
+// credential that doesn't exist is an error.
+func Execute(w io.Writer, name, text string, data *Data) error {
+	t, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(funcs(data)).
+		Parse(text)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, data)
+}
+
+func funcs(data *Data) template.FuncMap {
+	return template.FuncMap{
+		// cred returns a credential value, failing if it doesn't exist
+		"cred": func(key string) (string, error) {
+			v, ok := data.Credentials[key]
+			if !ok {
+				return "", fmt.Errorf("credential %q not found", key)
+			}
+			return v, nil
+		},
+
+		// credOr returns a credential value, or def when it doesn't exist:
+		// {{ credOr "PORT" "5432" }}
+		"credOr": func(key, def string) string {
+			if v, ok := data.Credentials[key]; ok {
+				return v
+			}
+			return def
+		},
+
+		// default returns the value, or def when the value is empty. It reads
+		// naturally in pipelines: {{ cred "HOST" | lower | default "localhost" }}
+		// Use credOr for credentials which may not exist.
+		"default": func(def string, value interface{}) string {
+			s := ""
+			if value != nil {
+				s = fmt.Sprint(value)
+			}
+			if s == "" {
+				return def
+			}
+			return s
+		},
+
+		// has returns true if the credential exists
+		"has": func(key string) bool {
+			_, ok := data.Credentials[key]
+			return ok
+		},
+
+		"quote":        strconv.Quote,
+		"squote":       squote,
+		"base64":       encode,
+		"base64decode": decode,
+		"upper":        strings.ToUpper,
+		"lower":        strings.ToLower,
+	}
+}
+
+func squote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func decode(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
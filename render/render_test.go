@@ -0,0 +1,94 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	data := &Data{
+		Credentials: map[string]string{
+			"DATABASE_URL": "postgres://db",
+			"PASSWORD":     "it's secret",
+		},
+		Resources: map[string]Resource{
+			"db": {Name: "db", Title: "Database", Credentials: map[string]string{"DATABASE_URL": "postgres://db"}},
+		},
+		Project: &Project{Name: "api"},
+	}
+
+	tcs := []struct {
+		scenario string
+		text     string
+		expected string
+		err      bool
+	}{
+		{
+			scenario: "when accessing credentials directly",
+			text:     "url: {{ .Credentials.DATABASE_URL }}",
+			expected: "url: postgres://db",
+		},
+		{
+			scenario: "when using the cred helper",
+			text:     `{{ cred "DATABASE_URL" | quote }}`,
+			expected: `"postgres://db"`,
+		},
+		{
+			scenario: "when quoting with single quotes",
+			text:     `{{ cred "PASSWORD" | squote }}`,
+			expected: `'it'\''s secret'`,
+		},
+		{
+			scenario: "when encoding in base64",
+			text:     `{{ cred "DATABASE_URL" | base64 }}`,
+			expected: "cG9zdGdyZXM6Ly9kYg==",
+		},
+		{
+			scenario: "when using a default value",
+			text:     `{{ if has "PORT" }}{{ cred "PORT" }}{{ else }}{{ "" | default "5432" }}{{ end }}`,
+			expected: "5432",
+		},
+		{
+			scenario: "when falling back for a missing credential",
+			text:     `{{ credOr "PORT" "5432" }} {{ credOr "DATABASE_URL" "none" }}`,
+			expected: "5432 postgres://db",
+		},
+		{
+			scenario: "when reading resource metadata",
+			text:     `{{ .Project.Name }}/{{ (index .Resources "db").Title }}`,
+			expected: "api/Database",
+		},
+		{
+			scenario: "when a credential is missing from the map",
+			text:     "{{ .Credentials.MISSING }}",
+			err:      true,
+		},
+		{
+			scenario: "when a credential is missing from cred",
+			text:     `{{ cred "MISSING" }}`,
+			err:      true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.scenario, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := Execute(buf, "test", tc.text, data)
+
+			if tc.err {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", buf.String())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if buf.String() != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, buf.String())
+			}
+		})
+	}
+}
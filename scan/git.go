@@ -0,0 +1,217 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Tree scans the files under root. Within a git repository, files ignored by
+// git are skipped, as they can't be committed by mistake. Files which can't
+// be read, or are too large to be scanned, are reported as skipped rather
+// than failing the scan.
+func (s *Scanner) Tree(root string) ([]Finding, []Skipped, error) {
+	var skipped []Skipped
+	files, err := gitFiles(root)
+	if err != nil {
+		files, skipped, err = walk(root)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var findings []Finding
+	for _, name := range files {
+		found, err := s.file(root, name)
+		if err != nil {
+			skipped = append(skipped, Skipped{Path: filepath.ToSlash(name), Reason: err.Error()})
+			continue
+		}
+
+		findings = append(findings, found...)
+	}
+
+	return findings, skipped, nil
+}
+
+// Staged scans the lines staged for the next commit under root, as a
+// pre-commit hook would. Like with Tree, paths are relative to root.
+func (s *Scanner) Staged(root string) ([]Finding, error) {
+	return s.diff(root, "diff", "--cached", "-U0", "--no-color", "--no-renames", "--no-ext-diff",
+		"--relative")
+}
+
+// History scans the lines added under root by every commit of the
+// repository, reporting the commit which added them. Like with Tree, paths
+// are relative to root.
+func (s *Scanner) History(root string) ([]Finding, error) {
+	return s.diff(root, "log", "--all", "-p", "-U0", "--no-color", "--no-renames",
+		"--no-ext-diff", "--relative", "--format=commit %H")
+}
+
+func (s *Scanner) file(root, name string) ([]Finding, error) {
+	f, err := os.Open(filepath.Join(root, name))
+	if os.IsNotExist(err) {
+		// Deleted files are still listed by git until the deletion is staged
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, err
+	}
+	if info.Size() > maxLine {
+		return nil, errTooLarge
+	}
+
+	// Binary files hold no lines worth reporting
+	r := bufio.NewReader(f)
+	head, _ := r.Peek(8000)
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil, nil
+	}
+
+	return s.Reader(filepath.ToSlash(name), r)
+}
+
+// diff scans the lines added within the output of a git command producing
+// patches, keeping track of their commit, file and line number.
+func (s *Scanner) diff(root string, args ...string) ([]Finding, error) {
+	cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	findings, err := s.patch(out)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+
+	return findings, nil
+}
+
+// patch scans the lines added within patches.
+func (s *Scanner) patch(r io.Reader) ([]Finding, error) {
+	var findings []Finding
+	var commit, path string
+	var line int
+	var header bool
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxLine)
+	for sc.Scan() {
+		// An added line may start with "++ ", headers always follow "--- "
+		b := sc.Bytes()
+		afterHeader := header
+		header = bytes.HasPrefix(b, []byte("--- "))
+
+		switch {
+		case bytes.HasPrefix(b, []byte("commit ")):
+			commit = string(b[len("commit "):])
+		case afterHeader && bytes.HasPrefix(b, []byte("+++ ")):
+			path = strings.TrimPrefix(string(b[len("+++ "):]), "b/")
+		case bytes.HasPrefix(b, []byte("@@ ")):
+			line = hunkStart(string(b))
+		case bytes.HasPrefix(b, []byte("+")):
+			for _, c := range s.Line(b[1:]) {
+				findings = append(findings, Finding{Credential: c, Path: path, Line: line,
+					Commit: commit})
+			}
+			line++
+		}
+	}
+
+	return findings, sc.Err()
+}
+
+// hunkStart returns the first line of the new file within a hunk header such
+// as "@@ -1,2 +3,4 @@".
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0
+	}
+
+	start := strings.SplitN(strings.TrimPrefix(fields[2], "+"), ",", 2)[0]
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+// gitFiles lists the tracked files, and untracked files which aren't ignored,
+// of the repository at root.
+func gitFiles(root string) ([]string, error) {
+	out, err := exec.Command("git", "-C", root, "ls-files", "-z", "--cached", "--others",
+		"--exclude-standard").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+
+	return files, nil
+}
+
+// walk lists the regular files under root, skipping the directories which
+// can't be read.
+func walk(root string) ([]string, []Skipped, error) {
+	var files []string
+	var skipped []Skipped
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return relErr
+		}
+
+		if err != nil {
+			if path == root {
+				return err
+			}
+
+			skipped = append(skipped, Skipped{Path: filepath.ToSlash(rel), Reason: err.Error()})
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, rel)
+		}
+		return nil
+	})
+
+	return files, skipped, err
+}
@@ -0,0 +1,163 @@
+// Package scan finds credential values within files and git history.
+//
+// Values are only held as hashes once added to a Scanner, and findings
+// identify the credential by resource and key, so secrets are never printed.
+package scan
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"sort"
+)
+
+// maxLine is the longest line read, files are only scanned up to this size
+const maxLine = 16 * 1024 * 1024
+
+// MinLength is the length under which values aren't searched for, as short
+// values such as ports or flags match all over a repository.
+const MinLength = 8
+
+// rolling hash base, any odd multiplier spreads windows well enough as every
+// match is confirmed with SHA-256
+const base = 1099511628211
+
+// Credential identifies a credential value by the resource it belongs to.
+type Credential struct {
+	Resource string `json:"resource" yaml:"resource"`
+	Key      string `json:"key" yaml:"key"`
+}
+
+// Finding is a credential value found within a file, or within the lines a
+// commit added to it.
+type Finding struct {
+	Credential
+	Path   string `json:"path" yaml:"path"`
+	Line   int    `json:"line" yaml:"line"`
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+}
+
+// Skipped is a file which could not be scanned.
+type Skipped struct {
+	Path   string `json:"path" yaml:"path"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// errTooLarge is the reason files over maxLine are skipped
+var errTooLarge = errors.New("file is larger than 16MB")
+
+type secret struct {
+	Credential
+	sum [sha256.Size]byte
+}
+
+// Scanner searches for credential values of any length in linear time per
+// distinct length, using a rolling hash of every window confirmed by its
+// SHA-256 sum.
+type Scanner struct {
+	lengths map[int]map[uint64][]secret
+}
+
+// New returns an empty Scanner.
+func New() *Scanner {
+	return &Scanner{lengths: make(map[int]map[uint64][]secret)}
+}
+
+// Add searches for the value of a credential. Values shorter than MinLength
+// are ignored.
+func (s *Scanner) Add(c Credential, value string) {
+	n := len(value)
+	if n < MinLength {
+		return
+	}
+
+	if s.lengths[n] == nil {
+		s.lengths[n] = make(map[uint64][]secret)
+	}
+
+	h := roll([]byte(value))
+	sum := sha256.Sum256([]byte(value))
+	for _, existing := range s.lengths[n][h] {
+		if existing.sum == sum && existing.Credential == c {
+			return
+		}
+	}
+
+	s.lengths[n][h] = append(s.lengths[n][h], secret{Credential: c, sum: sum})
+}
+
+// Empty returns whether there is no value to search for.
+func (s *Scanner) Empty() bool {
+	return len(s.lengths) == 0
+}
+
+// Reader returns the credentials found within the content of a file, one
+// finding per credential and line.
+func (s *Scanner) Reader(path string, r io.Reader) ([]Finding, error) {
+	var findings []Finding
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxLine)
+	for line := 1; sc.Scan(); line++ {
+		for _, c := range s.Line(sc.Bytes()) {
+			findings = append(findings, Finding{Credential: c, Path: path, Line: line})
+		}
+	}
+
+	return findings, sc.Err()
+}
+
+// Line returns the credentials whose value appears within the line.
+func (s *Scanner) Line(line []byte) []Credential {
+	var found []Credential
+	seen := make(map[Credential]bool)
+
+	for n, hashes := range s.lengths {
+		if len(line) < n {
+			continue
+		}
+
+		// pow is base^(n-1), removing the leading byte from the window
+		pow := uint64(1)
+		for i := 1; i < n; i++ {
+			pow *= base
+		}
+
+		h := roll(line[:n])
+		for i := 0; ; i++ {
+			if candidates, ok := hashes[h]; ok {
+				sum := sha256.Sum256(line[i : i+n])
+				for _, c := range candidates {
+					if c.sum == sum && !seen[c.Credential] {
+						seen[c.Credential] = true
+						found = append(found, c.Credential)
+					}
+				}
+			}
+
+			if i+n >= len(line) {
+				break
+			}
+			h = (h-uint64(line[i])*pow)*base + uint64(line[i+n])
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Resource != found[j].Resource {
+			return found[i].Resource < found[j].Resource
+		}
+		return found[i].Key < found[j].Key
+	})
+
+	return found
+}
+
+func roll(b []byte) uint64 {
+	var h uint64
+	for _, c := range b {
+		h = h*base + uint64(c)
+	}
+
+	return h
+}
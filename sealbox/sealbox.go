@@ -0,0 +1,160 @@
+// Package sealbox encrypts small files kept on disk with nacl/secretbox. The
+// key is derived with scrypt from a secret and a salt unique to every file.
+package sealbox
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KeySize is the size of derived keys and generated key files
+	KeySize = 32
+
+	// FilePermissions are the permissions of every file written
+	FilePermissions = 0600
+
+	saltSize  = 16
+	nonceSize = 24
+	version   = 1
+
+	// scrypt parameters
+	n = 32768
+	r = 8
+	p = 1
+)
+
+// ErrCorrupted is returned when a box or key file can't be read back
+var ErrCorrupted = errors.New("Sealed file could not be decrypted")
+
+type sealed struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Box     []byte `json:"box"`
+}
+
+// DeriveKey stretches the secret and salt into a KeySize byte key using
+// scrypt.
+func DeriveKey(secret, salt []byte) ([]byte, error) {
+	return scrypt.Key(secret, salt, n, r, p, KeySize)
+}
+
+// Seal encrypts plain with a key derived from secret and a random salt.
+func Seal(secret, plain []byte) ([]byte, error) {
+	s := &sealed{
+		Version: version,
+		Salt:    make([]byte, saltSize),
+		Nonce:   make([]byte, nonceSize),
+	}
+	if _, err := io.ReadFull(rand.Reader, s.Salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, s.Nonce); err != nil {
+		return nil, err
+	}
+
+	key, err := boxKey(secret, s.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [nonceSize]byte
+	copy(nonce[:], s.Nonce)
+	s.Box = secretbox.Seal(nil, plain, &nonce, key)
+
+	return json.Marshal(s)
+}
+
+// Open decrypts a box returned by Seal. ErrCorrupted is returned if the box
+// is malformed or was sealed with another secret.
+func Open(secret, b []byte) ([]byte, error) {
+	s := &sealed{}
+	if err := json.Unmarshal(b, s); err != nil || s.Version != version || len(s.Nonce) != nonceSize {
+		return nil, ErrCorrupted
+	}
+
+	key, err := boxKey(secret, s.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [nonceSize]byte
+	copy(nonce[:], s.Nonce)
+
+	plain, ok := secretbox.Open(nil, s.Box, &nonce, key)
+	if !ok {
+		return nil, ErrCorrupted
+	}
+
+	return plain, nil
+}
+
+// ReadKey reads a random key file, generating it when create is true and it
+// doesn't exist yet. When it doesn't exist and create is false, the returned
+// error satisfies os.IsNotExist.
+func ReadKey(path string, create bool) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		if len(b) != KeySize {
+			return nil, ErrCorrupted
+		}
+		return b, nil
+	case !os.IsNotExist(err) || !create:
+		return nil, err
+	}
+
+	b = make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, err
+	}
+
+	if err := WriteFile(path, b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// WriteFile writes through a temporary file so a failed write never leaves
+// a truncated file behind.
+func WriteFile(path string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Chmod(FilePermissions)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func boxKey(secret, salt []byte) (*[KeySize]byte, error) {
+	dk, err := DeriveKey(secret, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	var key [KeySize]byte
+	copy(key[:], dk)
+	return &key, nil
+}
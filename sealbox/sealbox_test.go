@@ -0,0 +1,62 @@
+package sealbox
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	b, err := Seal([]byte("secret"), []byte("plain"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if bytes.Contains(b, []byte("plain")) {
+		t.Error("Expected the box to be encrypted")
+	}
+
+	plain, err := Open([]byte("secret"), b)
+	if err != nil || string(plain) != "plain" {
+		t.Errorf("Expected plain, got %q %v", plain, err)
+	}
+
+	if _, err := Open([]byte("other"), b); err != ErrCorrupted {
+		t.Errorf("Expected ErrCorrupted with another secret, got %v", err)
+	}
+	if _, err := Open([]byte("secret"), []byte("{}")); err != ErrCorrupted {
+		t.Errorf("Expected ErrCorrupted for a malformed box, got %v", err)
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sealbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := filepath.Join(dir, "key")
+	if _, err := ReadKey(p, false); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error, got %v", err)
+	}
+
+	key, err := ReadKey(p, true)
+	if err != nil || len(key) != KeySize {
+		t.Fatalf("Expected a generated key, got %d bytes %v", len(key), err)
+	}
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != FilePermissions {
+		t.Errorf("Expected permissions %o, got %o", FilePermissions, fi.Mode().Perm())
+	}
+
+	again, err := ReadKey(p, false)
+	if err != nil || !bytes.Equal(key, again) {
+		t.Errorf("Expected the same key, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/manifoldco/manifold-cli/sealbox"
+)
+
+const (
+	keyFilename    = "key"
+	storeFilename  = "secrets"
+	dirPermissions = 0700
+
+	// EnvPassphrase holds a passphrase protecting the file store instead of
+	// the generated key file
+	EnvPassphrase = "MANIFOLD_SECRETS_PASSPHRASE"
+)
+
+// ErrCorrupted is returned when the file store can't be decrypted
+var ErrCorrupted = errors.New("Secrets could not be decrypted")
+
+// fileStore keeps every secret in a single sealbox file. The key is derived
+// from MANIFOLD_SECRETS_PASSPHRASE when it's set, and from a random key file
+// otherwise.
+//
+// The key file is kept next to the secrets, so it only protects against the
+// secrets file being copied or read on its own, such as in backups. Anyone
+// able to read the whole directory can decrypt it; a passphrase is required
+// for the secrets to be encrypted at rest.
+type fileStore struct {
+	dir string
+}
+
+func newFileStore(dir string) *fileStore {
+	return &fileStore{dir: dir}
+}
+
+func (s *fileStore) Name() string {
+	return File
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	secrets, err := s.read()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return secret, nil
+}
+
+func (s *fileStore) Set(key, secret string) error {
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	secrets[key] = secret
+	return s.write(secrets)
+}
+
+func (s *fileStore) Delete(key string) error {
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+
+	delete(secrets, key)
+	return s.write(secrets)
+}
+
+func (s *fileStore) read() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	b, err := ioutil.ReadFile(filepath.Join(s.dir, storeFilename))
+	switch {
+	case err == nil:
+	case os.IsNotExist(err):
+		return secrets, nil
+	default:
+		return nil, err
+	}
+
+	secret, err := s.secret(false)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := sealbox.Open(secret, b)
+	if err != nil {
+		if err == sealbox.ErrCorrupted {
+			err = ErrCorrupted
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, ErrCorrupted
+	}
+
+	return secrets, nil
+}
+
+func (s *fileStore) write(secrets map[string]string) error {
+	if err := os.MkdirAll(s.dir, dirPermissions); err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	secret, err := s.secret(true)
+	if err != nil {
+		return err
+	}
+
+	b, err := sealbox.Seal(secret, plain)
+	if err != nil {
+		return err
+	}
+
+	return sealbox.WriteFile(filepath.Join(s.dir, storeFilename), b)
+}
+
+// secret returns the passphrase when it's set, and the key file otherwise,
+// generating it when create is true.
+func (s *fileStore) secret(create bool) ([]byte, error) {
+	if secret := os.Getenv(EnvPassphrase); secret != "" {
+		return []byte(secret), nil
+	}
+
+	b, err := sealbox.ReadKey(filepath.Join(s.dir, keyFilename), create)
+	switch {
+	case err == nil:
+		return b, nil
+	case err == sealbox.ErrCorrupted || os.IsNotExist(err):
+		return nil, ErrCorrupted
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := Open(File, filepath.Join(dir, "store"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if _, err := s.Get("default"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.Set("default", "token"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := s.Set("client", "other"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	secret, err := s.Get("default")
+	if err != nil || secret != "token" {
+		t.Errorf("Expected token, got %q %v", secret, err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "store", storeFilename))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if bytes.Contains(b, []byte("token")) {
+		t.Error("Expected the secret to be encrypted on disk")
+	}
+
+	if err := s.Delete("default"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, err := s.Get("default"); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if secret, _ := s.Get("client"); secret != "other" {
+		t.Errorf("Expected other secrets to be kept, got %q", secret)
+	}
+
+	os.Setenv(EnvPassphrase, "passphrase")
+	defer os.Unsetenv(EnvPassphrase)
+	if _, err := s.Get("client"); err != ErrCorrupted {
+		t.Errorf("Expected ErrCorrupted with another key, got %v", err)
+	}
+}
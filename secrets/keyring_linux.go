@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secretTool talks to the Secret Service over D-Bus through secret-tool,
+// which ships with libsecret.
+type secretTool struct {
+	path string
+}
+
+func newKeyring() (Store, error) {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil, ErrUnavailable
+	}
+
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil, ErrUnavailable
+	}
+
+	return &secretTool{path: path}, nil
+}
+
+func (s *secretTool) Name() string {
+	return Keyring
+}
+
+func (s *secretTool) Get(key string) (string, error) {
+	out, err := s.run("", "lookup", "service", service, "key", key)
+
+	// lookup exits with a non-zero status and no output when nothing matches
+	if _, ok := err.(*exec.ExitError); ok {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if out == "" {
+		return "", ErrNotFound
+	}
+
+	return out, nil
+}
+
+func (s *secretTool) Set(key, secret string) error {
+	label := fmt.Sprintf("--label=Manifold CLI (%s)", key)
+	_, err := s.run(secret, "store", label, "service", service, "key", key)
+	if _, ok := err.(*exec.ExitError); ok {
+		return fmt.Errorf("Keyring error: could not store secret: %s", err)
+	}
+	return err
+}
+
+func (s *secretTool) Delete(key string) error {
+	_, err := s.run("", "clear", "service", service, "key", key)
+	if _, ok := err.(*exec.ExitError); ok {
+		return fmt.Errorf("Keyring error: could not clear secret: %s", err)
+	}
+	return err
+}
+
+// run executes secret-tool with stdin as input. Failures reported on stderr
+// are returned as keyring errors, silent ones as the *exec.ExitError.
+func (s *secretTool) run(stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(s.path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("Keyring error: %s", msg)
+		}
+		return "", err
+	}
+
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
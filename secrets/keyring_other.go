@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package secrets
+
+func newKeyring() (Store, error) {
+	return nil, ErrUnavailable
+}
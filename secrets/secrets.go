@@ -0,0 +1,65 @@
+// Package secrets keeps session tokens out of ~/.manifoldrc, either in the
+// keyring of the operating system or in an encrypted file.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// Keyring stores secrets in the keyring of the operating system, through
+	// the Secret Service API on Linux
+	Keyring = "keyring"
+
+	// File stores secrets in an encrypted file, for machines without a
+	// keyring
+	File = "file"
+
+	// Auto picks Keyring when it's available, and File otherwise
+	Auto = "auto"
+
+	service = "manifold-cli"
+)
+
+// ErrNotFound is returned when no secret is stored for a key
+var ErrNotFound = errors.New("Secret not found")
+
+// ErrUnavailable is returned when the keyring can't be reached
+var ErrUnavailable = errors.New("No keyring is available, use the file store instead")
+
+// Store reads and writes secrets by key.
+type Store interface {
+	// Name returns the name of the store, as accepted by Open
+	Name() string
+
+	// Get returns the secret for key, or ErrNotFound
+	Get(key string) (string, error)
+
+	// Set stores the secret for key, replacing any previous one
+	Set(key, secret string) error
+
+	// Delete removes the secret for key. Deleting a missing secret is not an
+	// error.
+	Delete(key string) error
+}
+
+// Open returns the store with the given name. The file store keeps its
+// secrets inside dir.
+func Open(name, dir string) (Store, error) {
+	switch name {
+	case Keyring:
+		return newKeyring()
+	case File:
+		return newFileStore(dir), nil
+	case Auto:
+		s, err := newKeyring()
+		if err == ErrUnavailable {
+			return newFileStore(dir), nil
+		}
+		return s, err
+	default:
+		return nil, fmt.Errorf("Unknown secret store %q, expected %s, %s or %s",
+			name, Keyring, File, Auto)
+	}
+}
@@ -0,0 +1,94 @@
+// Package subscription decodes the subscription event log of the billing
+// service, and reconstructs the credit balance of coupon applications from it.
+package subscription
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+)
+
+// CreditEvent is the only event type the billing service lists.
+const CreditEvent = "credit"
+
+// Event is an entry of the subscription event log.
+type Event struct {
+	ID        manifold.ID `json:"id"`
+	Version   int         `json:"version"`
+	Type      string      `json:"type"`
+	Body      Body        `json:"body"`
+	Signature Signature   `json:"signature"`
+
+	// body and signature are kept as sent, as the ID and signature of the
+	// event cover them rather than the fields known to Body
+	body      json.RawMessage
+	signature json.RawMessage
+}
+
+// UnmarshalJSON decodes an event, keeping its body and signature as sent.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	type event Event
+	if err := json.Unmarshal(b, (*event)(e)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Body      json.RawMessage `json:"body"`
+		Signature json.RawMessage `json:"signature"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	e.body = raw.Body
+	e.signature = raw.Signature
+	return nil
+}
+
+// Body holds the fields of every event type, credit fields are empty for
+// other types.
+type Body struct {
+	EventType   string       `json:"event_type"`
+	EventNumber int64        `json:"event_number"`
+	ParentEvent *manifold.ID `json:"parent_event,omitempty"`
+	OperationID manifold.ID  `json:"operation_id"`
+	OccurredAt  time.Time    `json:"occurred_at"`
+	UserID      *manifold.ID `json:"user_id,omitempty"`
+	TeamID      *manifold.ID `json:"team_id,omitempty"`
+	ProviderID  *manifold.ID `json:"provider_id,omitempty"`
+	ResourceID  *manifold.ID `json:"resource_id,omitempty"`
+	RolloverID  *manifold.ID `json:"rollover_id,omitempty"`
+
+	// Amount is the value of a credit in cents, the remaining balance for
+	// rollovers
+	Amount   int64        `json:"amount"`
+	Currency string       `json:"currency,omitempty"`
+	Reason   string       `json:"reason,omitempty"`
+	CouponID *manifold.ID `json:"coupon_id,omitempty"`
+	Code     string       `json:"code,omitempty"`
+}
+
+// Signature is the signature of an event by Manifold.
+type Signature struct {
+	Alg         string `json:"alg"`
+	Value       string `json:"value"`
+	PublicKey   string `json:"public_key"`
+	Endorsement string `json:"endorsement"`
+}
+
+// Decode returns the events of a list as returned by the billing service,
+// ordered by event number.
+func Decode(b []byte) ([]Event, error) {
+	var events []Event
+	if err := json.Unmarshal(b, &events); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Body.EventNumber < events[j].Body.EventNumber
+	})
+
+	return events, nil
+}
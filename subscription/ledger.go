@@ -0,0 +1,139 @@
+package subscription
+
+import (
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+)
+
+// periodFormat names billing periods, which are calendar months in UTC.
+const periodFormat = "2006-01"
+
+// Application is a coupon applied to an account, or a credit granted by
+// Manifold, along with what remains of it.
+type Application struct {
+	ID        manifold.ID
+	CouponID  *manifold.ID
+	Code      string
+	Reason    string
+	AppliedAt time.Time
+
+	// Amount is the credit applied, Balance what remains of it
+	Amount  int64
+	Balance int64
+
+	// ExhaustedIn is the billing period the credit was used up in, empty
+	// while a balance remains
+	ExhaustedIn string
+
+	Periods []Period
+}
+
+// Period is what a credit was worth at the start of a billing period, and
+// what was used during it. Used is only known once the period closed.
+//
+// ExpiresAt is the end of the billing period, when what remains of the credit
+// is rolled over into the next period, and lost when nothing remains.
+type Period struct {
+	Period    string
+	Opening   int64
+	Used      int64
+	Closed    bool
+	ExpiresAt time.Time
+}
+
+// Ledger reconstructs the balance of every credit application from credit
+// events ordered by event number.
+//
+// Rollover events carry the balance of an application into a new billing
+// period, and reference the previous event of the application. Applications
+// without a rollover into the current period were used up in the period of
+// their last event, though rollovers are only expected from the 2nd of the
+// month to allow for processing delays.
+//
+// Rollovers referencing an event missing from the list can't be attributed
+// to an application, and are returned as orphans rather than counted.
+func Ledger(events []Event, now time.Time) (apps []*Application, orphans []Event) {
+	latest := make(map[manifold.ID]*Application)
+
+	for _, e := range events {
+		if e.Body.EventType != CreditEvent {
+			continue
+		}
+
+		b := e.Body
+		period := b.OccurredAt.UTC().Format(periodFormat)
+
+		var app *Application
+		if b.RolloverID != nil {
+			app = latest[*b.RolloverID]
+			delete(latest, *b.RolloverID)
+
+			if app == nil {
+				orphans = append(orphans, e)
+				continue
+			}
+		}
+
+		if app == nil {
+			app = &Application{
+				ID:        e.ID,
+				CouponID:  b.CouponID,
+				Code:      b.Code,
+				Reason:    b.Reason,
+				AppliedAt: b.OccurredAt,
+				Amount:    b.Amount,
+			}
+			apps = append(apps, app)
+		} else {
+			last := &app.Periods[len(app.Periods)-1]
+			last.Used = last.Opening - b.Amount
+			last.Closed = true
+		}
+
+		app.Balance = b.Amount
+		app.Periods = append(app.Periods, Period{
+			Period:    period,
+			Opening:   b.Amount,
+			ExpiresAt: periodEnd(b.OccurredAt),
+		})
+		latest[e.ID] = app
+	}
+
+	now = now.UTC()
+	current := now.Format(periodFormat)
+	previous := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).
+		Format(periodFormat)
+
+	for _, app := range apps {
+		last := &app.Periods[len(app.Periods)-1]
+		pending := now.Day() < 2 && last.Period == previous
+		if last.Period == current || pending {
+			continue
+		}
+
+		// No rollover followed the last period, so nothing remained of it
+		last.Used = last.Opening
+		last.Closed = true
+		app.Balance = 0
+		app.ExhaustedIn = last.Period
+	}
+
+	return apps, orphans
+}
+
+// periodEnd returns the end of the billing period t falls in.
+func periodEnd(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+}
+
+// Remaining returns the credit remaining across applications.
+func Remaining(apps []*Application) int64 {
+	var total int64
+	for _, app := range apps {
+		total += app.Balance
+	}
+
+	return total
+}
@@ -0,0 +1,88 @@
+package subscription
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+)
+
+func newID(t *testing.T) manifold.ID {
+	id, err := manifold.NewID(idtype.Operation)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return id
+}
+
+func TestLedger(t *testing.T) {
+	coupon := newID(t)
+	applied := newID(t)
+	rollover := newID(t)
+	used := newID(t)
+	general := newID(t)
+
+	b := []byte(fmt.Sprintf(`[
+		{"id":%q,"body":{"event_type":"credit","event_number":3,"occurred_at":"2026-10-01T04:00:00Z",
+			"amount":2500,"coupon_id":%q,"code":"WELCOME","rollover_id":%q}},
+		{"id":%q,"body":{"event_type":"credit","event_number":1,"occurred_at":"2026-09-12T10:00:00Z",
+			"amount":5000,"coupon_id":%q,"code":"WELCOME"}},
+		{"id":%q,"body":{"event_type":"credit","event_number":2,"occurred_at":"2026-09-20T10:00:00Z",
+			"amount":1000,"coupon_id":%q,"code":"WELCOME"}},
+		{"id":%q,"body":{"event_type":"credit","event_number":4,"occurred_at":"2026-10-03T10:00:00Z",
+			"amount":700,"reason":"outage"}}
+	]`, rollover, coupon, applied, applied, coupon, used, coupon, general))
+
+	events, err := Decode(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	apps, orphans := Ledger(events, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+	if len(apps) != 3 || len(orphans) != 0 {
+		t.Fatalf("Expected 3 applications and no orphans, got %d and %d", len(apps), len(orphans))
+	}
+
+	first, second, third := apps[0], apps[1], apps[2]
+	if first.ID != applied || first.Amount != 5000 || first.Balance != 2500 || first.ExhaustedIn != "" {
+		t.Errorf("Expected the rollover to carry the balance, got %+v", first)
+	}
+	if len(first.Periods) != 2 || first.Periods[0] != (Period{Period: "2026-09", Opening: 5000,
+		Used: 2500, Closed: true, ExpiresAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}) {
+		t.Errorf("Unexpected periods %+v", first.Periods)
+	}
+	if !first.Periods[1].ExpiresAt.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the balance to expire at the end of October, got %s",
+			first.Periods[1].ExpiresAt)
+	}
+
+	if second.ID != used || second.Balance != 0 || second.ExhaustedIn != "2026-09" ||
+		second.Periods[0].Used != 1000 {
+		t.Errorf("Expected an application without rollover to be used up, got %+v", second)
+	}
+
+	if third.Code != "" || third.Reason != "outage" || third.Balance != 700 {
+		t.Errorf("Expected the general credit, got %+v", third)
+	}
+
+	if r := Remaining(apps); r != 3200 {
+		t.Errorf("Expected 3200 remaining, got %d", r)
+	}
+
+	// Rollovers are still expected on the 1st
+	apps, _ = Ledger(events[:2], time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	if apps[1].Balance != 1000 || apps[1].ExhaustedIn != "" {
+		t.Errorf("Expected the rollover to be pending, got %+v", apps[1])
+	}
+
+	// Rollovers of applications missing from the list aren't attributed
+	apps, orphans = Ledger(events[2:], time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+	if len(apps) != 1 || apps[0].ID != general {
+		t.Errorf("Expected only the general credit, got %+v", apps)
+	}
+	if len(orphans) != 1 || orphans[0].ID != rollover {
+		t.Errorf("Expected the rollover to be an orphan, got %+v", orphans)
+	}
+}
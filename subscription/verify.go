@@ -0,0 +1,140 @@
+package subscription
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/manifoldco/go-manifold"
+	"github.com/manifoldco/go-manifold/idtype"
+	"golang.org/x/crypto/ed25519"
+)
+
+// ErrNoPublicKey is returned when no Manifold public key is available to
+// verify events against.
+var ErrNoPublicKey = errors.New("No Manifold public key to verify events against")
+
+// BrokenLink describes the first event of a log which can't be trusted.
+type BrokenLink struct {
+	Event  Event
+	Reason string
+}
+
+func (b *BrokenLink) Error() string {
+	return fmt.Sprintf("Event %d (%s) %s", b.Event.Body.EventNumber, b.Event.ID, b.Reason)
+}
+
+// ParsePublicKey decodes an ed25519 public key encoded in URL safe base64,
+// as keys are throughout the Manifold API.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	if s == "" {
+		return nil, ErrNoPublicKey
+	}
+
+	b, err := decode(s)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, errors.New("Invalid Manifold public key, expected a base64 ed25519 key")
+	}
+
+	return ed25519.PublicKey(b), nil
+}
+
+// Verify walks events ordered by event number, returning a *BrokenLink for
+// the first event which doesn't hold up. For every event:
+//
+//   - its parent must be the event before it, and the first event must have
+//     none, so a log missing its head doesn't verify
+//   - its ID must be the digest of its version, body and signature
+//   - its signing key must be endorsed by the Manifold key
+//   - its body must be signed by its signing key
+func Verify(events []Event, key ed25519.PublicKey) error {
+	for i, e := range events {
+		if i == 0 && e.Body.ParentEvent != nil {
+			return &BrokenLink{Event: e, Reason: fmt.Sprintf(
+				"follows event %s which is missing, the log doesn't start at its root",
+				*e.Body.ParentEvent)}
+		}
+		if i > 0 {
+			prev := events[i-1]
+			if e.Body.EventNumber <= prev.Body.EventNumber {
+				return &BrokenLink{Event: e, Reason: fmt.Sprintf(
+					"is numbered after event %d", prev.Body.EventNumber)}
+			}
+			if e.Body.ParentEvent == nil || *e.Body.ParentEvent != prev.ID {
+				return &BrokenLink{Event: e, Reason: fmt.Sprintf(
+					"does not follow event %d (%s)", prev.Body.EventNumber, prev.ID)}
+			}
+		}
+
+		if reason := verifyEvent(e, key); reason != "" {
+			return &BrokenLink{Event: e, Reason: reason}
+		}
+	}
+
+	return nil
+}
+
+func verifyEvent(e Event, key ed25519.PublicKey) string {
+	if e.body == nil || e.signature == nil {
+		return "is missing its body or signature"
+	}
+
+	id, err := manifold.NewImmutableID(immutable{e}, e.signature)
+	if err != nil || id != e.ID {
+		return "does not match its ID, its content was changed"
+	}
+
+	if e.Signature.Alg != "eddsa" {
+		return fmt.Sprintf("is signed with unsupported algorithm %q", e.Signature.Alg)
+	}
+
+	pub, err := decode(e.Signature.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return "has an invalid signing key"
+	}
+
+	endorsement, err := decode(e.Signature.Endorsement)
+	if err != nil || !ed25519.Verify(key, pub, endorsement) {
+		return "is signed with a key Manifold did not endorse"
+	}
+
+	sig, err := decode(e.Signature.Value)
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(pub), compact(e.body), sig) {
+		return "has an invalid signature"
+	}
+
+	return ""
+}
+
+// immutable identifies an event the way the billing service derived its ID.
+type immutable struct {
+	e Event
+}
+
+func (i immutable) GetID() manifold.ID   { return i.e.ID }
+func (i immutable) Version() int         { return i.e.Version }
+func (i immutable) Type() idtype.Type    { return idtype.SubscriptionEvent }
+func (i immutable) GetBody() interface{} { return i.e.body }
+func (i immutable) Immutable()           {}
+
+// decode reads URL safe base64, with or without padding.
+func decode(s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return base64.URLEncoding.DecodeString(s)
+	}
+
+	return b, nil
+}
+
+// compact returns JSON as encoding/json writes it, which is what was signed.
+func compact(b []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return b
+	}
+
+	return buf.Bytes()
+}
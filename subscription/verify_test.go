@@ -0,0 +1,147 @@
+package subscription
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manifoldco/go-manifold"
+	"golang.org/x/crypto/ed25519"
+)
+
+// signedLog signs events the way the billing service does, chaining each to
+// the one before it.
+func signedLog(t *testing.T, root ed25519.PrivateKey, amounts ...int64) []byte {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	var events []json.RawMessage
+	var parent *manifold.ID
+	for i, amount := range amounts {
+		body, err := json.Marshal(Body{
+			EventType:   CreditEvent,
+			EventNumber: int64(i),
+			ParentEvent: parent,
+			OccurredAt:  time.Date(2026, 9, 1+i, 0, 0, 0, 0, time.UTC),
+			Amount:      amount,
+			Currency:    "usd",
+			Reason:      "coupon",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		sig, err := json.Marshal(Signature{
+			Alg:         "eddsa",
+			Value:       encode(ed25519.Sign(priv, body)),
+			PublicKey:   encode(pub),
+			Endorsement: encode(ed25519.Sign(root, pub)),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		e := Event{Version: 1, body: body, signature: sig}
+		id, err := manifold.NewImmutableID(immutable{e}, e.signature)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		b, err := json.Marshal(map[string]interface{}{
+			"id":        id,
+			"version":   1,
+			"type":      "subscription_event",
+			"body":      json.RawMessage(body),
+			"signature": json.RawMessage(sig),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		events = append(events, b)
+		parent = &id
+	}
+
+	b, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	return b
+}
+
+func TestVerify(t *testing.T) {
+	rootPub, root, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	log := signedLog(t, root, 5000, 2500, 1000)
+
+	decode := func(b []byte) []Event {
+		events, err := Decode(b)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		return events
+	}
+
+	key, err := ParsePublicKey(base64.RawURLEncoding.EncodeToString(rootPub))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		if err := Verify(decode(log), key); err != nil {
+			t.Errorf("Expected the log to verify, got %s", err)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := strings.Replace(string(log), `"amount": 2500`, `"amount": 9500`, 1)
+		err := Verify(decode([]byte(tampered)), key)
+		if b, ok := err.(*BrokenLink); !ok || b.Event.Body.EventNumber != 1 ||
+			!strings.Contains(b.Reason, "ID") {
+			t.Errorf("Expected event 1 to fail its ID, got %v", err)
+		}
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		events := decode(log)
+		err := Verify(append(events[:1], events[2:]...), key)
+		if b, ok := err.(*BrokenLink); !ok || b.Event.Body.EventNumber != 2 ||
+			!strings.Contains(b.Reason, "follow") {
+			t.Errorf("Expected event 2 to break the chain, got %v", err)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		err := Verify(decode(log)[1:], key)
+		if b, ok := err.(*BrokenLink); !ok || b.Event.Body.EventNumber != 1 ||
+			!strings.Contains(b.Reason, "root") {
+			t.Errorf("Expected event 1 to be refused as the head, got %v", err)
+		}
+	})
+
+	t.Run("unendorsed", func(t *testing.T) {
+		_, other, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		err = Verify(decode(signedLog(t, other, 100)), key)
+		if b, ok := err.(*BrokenLink); !ok || !strings.Contains(b.Reason, "endorse") {
+			t.Errorf("Expected the signing key to be refused, got %v", err)
+		}
+	})
+
+	if _, err := ParsePublicKey(""); err != ErrNoPublicKey {
+		t.Errorf("Expected ErrNoPublicKey, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+// Package transport provides the http.RoundTripper middleware shared by every
+// API client.
+package transport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultPolicy is used for the values of a Policy which aren't set.
+var DefaultPolicy = Policy{
+	Retries:  3,
+	Timeout:  30 * time.Second,
+	MinDelay: 250 * time.Millisecond,
+	MaxDelay: 10 * time.Second,
+}
+
+// maxRetryAfter bounds how long a Retry-After header can make us wait.
+const maxRetryAfter = time.Minute
+
+// Policy controls how failed requests are retried.
+type Policy struct {
+	// Retries is the number of times a request is retried. Use a negative
+	// value to disable retries.
+	Retries int
+
+	// Timeout bounds every attempt, including reading the response body.
+	Timeout time.Duration
+
+	// MinDelay and MaxDelay bound the exponential backoff between attempts.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+}
+
+// ExhaustedError is returned once a request failed on every attempt.
+type ExhaustedError struct {
+	Attempts int
+
+	// Status is set when the last attempt received a response, otherwise
+	// Err holds the reason it failed.
+	Status string
+	Err    error
+}
+
+func (e *ExhaustedError) Error() string {
+	reason := e.Status
+	if e.Err != nil {
+		reason = e.Err.Error()
+	}
+
+	return fmt.Sprintf("gave up after %d attempt(s): %s", e.Attempts, reason)
+}
+
+// NewRetry returns a RoundTripper retrying requests which failed due to rate
+// limits, gateway errors or network errors. Requests are retried with
+// jittered exponential backoff, waiting for Retry-After when it's set.
+//
+// Requests rejected by a rate limit are always retried, others only when
+// their method is idempotent.
+func NewRetry(next http.RoundTripper, p Policy) http.RoundTripper {
+	if p.Retries == 0 {
+		p.Retries = DefaultPolicy.Retries
+	}
+	if p.Retries < 0 {
+		p.Retries = 0
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = DefaultPolicy.Timeout
+	}
+	if p.MinDelay <= 0 {
+		p.MinDelay = DefaultPolicy.MinDelay
+	}
+	if p.MaxDelay < p.MinDelay {
+		p.MaxDelay = p.MinDelay
+	}
+
+	return &retry{next: next, policy: p}
+}
+
+type retry struct {
+	next   http.RoundTripper
+	policy Policy
+}
+
+func (rt *retry) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	parent := req.Context()
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(parent, rt.policy.Timeout)
+		r := req.WithContext(ctx)
+		if body != nil {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		res, err := rt.next.RoundTrip(r)
+		if parent.Err() != nil {
+			cancel()
+			return nil, parent.Err()
+		}
+
+		wait, retryable := rt.backoff(req, attempt, res, err)
+		if !retryable {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+
+			res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+			return res, nil
+		}
+
+		exhausted := &ExhaustedError{Attempts: attempt + 1, Err: err}
+		if res != nil {
+			exhausted.Status = res.Status
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		cancel()
+
+		if attempt >= rt.policy.Retries || wait > maxRetryAfter {
+			return nil, exhausted
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-parent.Done():
+			return nil, parent.Err()
+		}
+	}
+}
+
+// backoff returns whether the attempt should be retried, and how long to wait
+// before doing so.
+func (rt *retry) backoff(req *http.Request, attempt int, res *http.Response, err error) (time.Duration, bool) {
+	idempotent := isIdempotent(req.Method)
+
+	switch {
+	case err != nil:
+		if !idempotent {
+			return 0, false
+		}
+	case res.StatusCode == http.StatusTooManyRequests:
+		if d, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			return d, true
+		}
+	case res.StatusCode == http.StatusBadGateway,
+		res.StatusCode == http.StatusServiceUnavailable,
+		res.StatusCode == http.StatusGatewayTimeout:
+		if !idempotent {
+			return 0, false
+		}
+		if d, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			return d, true
+		}
+	default:
+		return 0, false
+	}
+
+	d := rt.policy.MinDelay << uint(attempt)
+	if d > rt.policy.MaxDelay || d <= 0 {
+		d = rt.policy.MaxDelay
+	}
+
+	// Wait between half and all of the delay, so concurrent requests don't
+	// retry in lockstep
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int63n(half+1)), true
+}
+
+// retryAfter parses a Retry-After header holding either seconds or a date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// cancelBody releases the context of an attempt once its response body is
+// closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,114 @@
+package transport
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	policy := Policy{Retries: 2, MinDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+	serve := func(statuses ...int) (*httptest.Server, *int32) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&calls, 1)
+			b, _ := ioutil.ReadAll(r.Body)
+
+			status := statuses[len(statuses)-1]
+			if int(n) <= len(statuses) {
+				status = statuses[n-1]
+			}
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(status)
+			w.Write(b)
+		}))
+		return srv, &calls
+	}
+
+	client := &http.Client{Transport: NewRetry(http.DefaultTransport, policy)}
+
+	t.Run("retries idempotent requests", func(t *testing.T) {
+		srv, calls := serve(http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+		defer srv.Close()
+
+		req, _ := http.NewRequest("PUT", srv.URL, strings.NewReader("body"))
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		defer res.Body.Close()
+
+		b, _ := ioutil.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK || string(b) != "body" {
+			t.Errorf("Expected the body to be resent, got %d %q", res.StatusCode, b)
+		}
+		if *calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", *calls)
+		}
+	})
+
+	t.Run("does not retry other requests on gateway errors", func(t *testing.T) {
+		srv, calls := serve(http.StatusBadGateway, http.StatusOK)
+		defer srv.Close()
+
+		res, err := client.Post(srv.URL, "text/plain", strings.NewReader("body"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusBadGateway || *calls != 1 {
+			t.Errorf("Expected a single attempt, got %d with %d", *calls, res.StatusCode)
+		}
+	})
+
+	t.Run("retries rate limited requests", func(t *testing.T) {
+		srv, calls := serve(http.StatusTooManyRequests, http.StatusCreated)
+		defer srv.Close()
+
+		res, err := client.Post(srv.URL, "text/plain", strings.NewReader("body"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated || *calls != 2 {
+			t.Errorf("Expected a retry, got %d with %d", *calls, res.StatusCode)
+		}
+	})
+
+	t.Run("gives up once the budget is exhausted", func(t *testing.T) {
+		srv, calls := serve(http.StatusServiceUnavailable)
+		defer srv.Close()
+
+		_, err := client.Get(srv.URL)
+		if err == nil || !strings.Contains(err.Error(), "gave up after 3 attempt(s): 503") {
+			t.Errorf("Expected the retries to be exhausted, got %v", err)
+		}
+		if *calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", *calls)
+		}
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d, ok := retryAfter("2"); !ok || d != 2*time.Second {
+		t.Errorf("Expected 2s, got %s", d)
+	}
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d, ok := retryAfter(date); !ok || d < 59*time.Minute {
+		t.Errorf("Expected about an hour, got %s", d)
+	}
+
+	if _, ok := retryAfter("soon"); ok {
+		t.Error("Expected an invalid value to be ignored")
+	}
+}
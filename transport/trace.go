@@ -0,0 +1,278 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are never written to a trace.
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// sensitiveKeys are the parts of JSON keys and query parameters holding
+// secrets, such as auth_token, login_token_sig, private_key or a coupon code.
+var sensitiveKeys = []string{"password", "token", "secret", "sig", "salt", "card", "stripe",
+	"key", "code"}
+
+// publicKeys are JSON keys and query parameters matching sensitiveKeys which
+// are known not to hold secrets.
+var publicKeys = map[string]bool{
+	"public_key": true,
+}
+
+// secretMaps are JSON keys holding a map of secrets, such as the values of a
+// credential. Their keys are kept while their values are redacted.
+var secretMaps = map[string]bool{
+	"values": true,
+}
+
+// tokenPaths maps path segments followed by a secret, such as the token
+// revoked by DELETE /tokens/{token}, to the segments which aren't secrets.
+var tokenPaths = map[string][]string{
+	"tokens":  {"login", "auth", "oauth"},
+	"invites": {"accept"},
+}
+
+// requestIDHeaders hold the ID support uses to find a request.
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id"}
+
+// Tracer writes every request and response to a writer, redacting secrets so
+// traces can be shared.
+type Tracer struct {
+	mu     sync.Mutex
+	w      io.Writer
+	bodies bool
+	n      int
+}
+
+// NewTracer returns a Tracer writing to w. Request and response bodies are
+// only written when bodies is set.
+func NewTracer(w io.Writer, bodies bool) *Tracer {
+	return &Tracer{w: w, bodies: bodies}
+}
+
+// Wrap returns a RoundTripper tracing the requests sent through next.
+func (t *Tracer) Wrap(next http.RoundTripper) http.RoundTripper {
+	return &trace{tracer: t, next: next}
+}
+
+type trace struct {
+	tracer *Tracer
+	next   http.RoundTripper
+}
+
+func (rt *trace) RoundTrip(req *http.Request) (*http.Response, error) {
+	t := rt.tracer
+
+	t.mu.Lock()
+	t.n++
+	n := t.n
+	t.mu.Unlock()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "[%d] --> %s %s\n", n, req.Method, redactURL(req.URL))
+	writeHeaders(&buf, n, req.Header)
+
+	if t.bodies && req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		writeBody(&buf, n, req.URL, req.Header, b)
+	}
+	t.write(buf.Bytes())
+
+	start := time.Now()
+	res, err := rt.next.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	buf.Reset()
+	if err != nil {
+		fmt.Fprintf(&buf, "[%d] <-- error after %s: %s\n", n, elapsed, err)
+		t.write(buf.Bytes())
+		return nil, err
+	}
+
+	fmt.Fprintf(&buf, "[%d] <-- %s (%s)", n, res.Status, elapsed)
+	for _, h := range requestIDHeaders {
+		if id := res.Header.Get(h); id != "" {
+			fmt.Fprintf(&buf, " request-id=%s", id)
+			break
+		}
+	}
+	buf.WriteString("\n")
+	writeHeaders(&buf, n, res.Header)
+
+	if t.bodies && res.Body != nil {
+		b, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		res.Body = ioutil.NopCloser(bytes.NewReader(b))
+		writeBody(&buf, n, req.URL, res.Header, b)
+	}
+	t.write(buf.Bytes())
+
+	return res, nil
+}
+
+// write writes a whole entry at once, so the entries of concurrent requests
+// aren't interleaved.
+func (t *Tracer) write(b []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.w.Write(b)
+}
+
+func writeHeaders(w io.Writer, n int, h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(h[name], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] || isSensitive(name) {
+			value = redacted
+		}
+		fmt.Fprintf(w, "[%d]     %s: %s\n", n, name, value)
+	}
+}
+
+func writeBody(w io.Writer, n int, u *url.URL, h http.Header, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+
+	if !strings.Contains(h.Get("Content-Type"), "json") {
+		fmt.Fprintf(w, "[%d]     [%d byte body]\n", n, len(b))
+		return
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		fmt.Fprintf(w, "[%d]     [%d byte body]\n", n, len(b))
+		return
+	}
+
+	// Custom resource configs are maps of secrets
+	if strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), "/config") {
+		v = redactValues(v)
+	} else {
+		v = redact(v)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "[%d]     %s\n", n, out)
+}
+
+// redact replaces the values of sensitive keys found in a decoded JSON value.
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			switch {
+			case isSensitive(k):
+				v[k] = redacted
+			case secretMaps[k]:
+				v[k] = redactValues(val)
+			default:
+				v[k] = redact(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redact(val)
+		}
+	}
+
+	return v
+}
+
+// redactValues replaces every value of a map, keeping its keys.
+func redactValues(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return redacted
+	}
+
+	for k := range m {
+		m[k] = redacted
+	}
+	return m
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+
+	segments := strings.Split(c.Path, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		public, ok := tokenPaths[segments[i]]
+		if !ok || segments[i+1] == "" || contains(public, segments[i+1]) {
+			continue
+		}
+		segments[i+1] = redacted
+		i++
+	}
+	c.Path = strings.Join(segments, "/")
+	c.RawPath = ""
+
+	q := c.Query()
+	for k := range q {
+		if isSensitive(k) {
+			q[k] = []string{redacted}
+		}
+	}
+	c.RawQuery = q.Encode()
+	c.User = nil
+
+	s, err := url.PathUnescape(c.String())
+	if err != nil {
+		return c.String()
+	}
+	return s
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	if publicKeys[name] {
+		return false
+	}
+
+	for _, k := range sensitiveKeys {
+		if strings.Contains(name, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
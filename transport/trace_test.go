@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTracer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-123")
+		w.Write([]byte(`{"body":{"token":"auth-secret","values":{"DB_URL":"postgres://secret"},"label":"db"}}`))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	client := &http.Client{Transport: NewTracer(&buf, true).Wrap(http.DefaultTransport)}
+
+	req, _ := http.NewRequest("POST", srv.URL+"/tokens/login?api_token=query-secret&team_id=1",
+		strings.NewReader(`{"email":"dev@example.com","password":"hunter2"}`))
+	req.Header.Set("Authorization", "Bearer bearer-secret")
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer res.Body.Close()
+
+	b, _ := ioutil.ReadAll(res.Body)
+	if !strings.Contains(string(b), "auth-secret") {
+		t.Errorf("Expected the response body to be left untouched, got %s", b)
+	}
+
+	trace := buf.String()
+	for _, secret := range []string{"query-secret", "bearer-secret", "hunter2", "auth-secret", "postgres://secret"} {
+		if strings.Contains(trace, secret) {
+			t.Errorf("Expected %q to be redacted from:\n%s", secret, trace)
+		}
+	}
+	for _, expected := range []string{"--> POST", "/tokens/login", "team_id=1", "dev@example.com",
+		"<-- 200 OK", "request-id=req-123", `"DB_URL":"[REDACTED]"`, `"label":"db"`} {
+		if !strings.Contains(trace, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, trace)
+		}
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	req, _ := http.NewRequest("DELETE", "https://api.identity.manifold.co/v1/tokens/auth-secret", nil)
+	if u := redactURL(req.URL); strings.Contains(u, "auth-secret") || !strings.Contains(u, "/tokens/[REDACTED]") {
+		t.Errorf("Expected the token to be redacted, got %s", u)
+	}
+
+	req, _ = http.NewRequest("POST", "https://api.identity.manifold.co/v1/tokens/auth", nil)
+	if u := redactURL(req.URL); !strings.HasSuffix(u, "/tokens/auth") {
+		t.Errorf("Expected the path to be kept, got %s", u)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	v := redact(map[string]interface{}{
+		"private_key": "key-secret",
+		"public_key":  "public",
+		"code":        "code-secret",
+		"coupon": map[string]interface{}{
+			"code":  "coupon-secret",
+			"label": "launch",
+		},
+	}).(map[string]interface{})
+
+	for _, k := range []string{"private_key", "code"} {
+		if v[k] != redacted {
+			t.Errorf("Expected %s to be redacted, got %v", k, v[k])
+		}
+	}
+	if v["public_key"] != "public" {
+		t.Errorf("Expected public_key to be kept, got %v", v["public_key"])
+	}
+
+	coupon := v["coupon"].(map[string]interface{})
+	if coupon["code"] != redacted || coupon["label"] != "launch" {
+		t.Errorf("Expected only the coupon code to be redacted, got %v", coupon)
+	}
+}